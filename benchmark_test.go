@@ -0,0 +1,204 @@
+package jason
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// An object with n members, one of them holding a nested object of n members.
+func wideDocument(n int) []byte {
+	var b strings.Builder
+	b.WriteString(`{"a": {"b": {"c": "found"`)
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, `, "key%d": %d`, i, i)
+	}
+	b.WriteString(`}`)
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, `, "key%d": "value %d"`, i, i)
+	}
+	b.WriteString(`}}`)
+	return []byte(b.String())
+}
+
+// Objects nested depth levels deep, each with a few siblings.
+func deepDocument(depth int) ([]byte, []string) {
+	var b strings.Builder
+	keys := make([]string, depth)
+	for i := 0; i < depth; i++ {
+		keys[i] = "level" + strconv.Itoa(i)
+		fmt.Fprintf(&b, `{"x": 1, "y": [1, 2], "%s": `, keys[i])
+	}
+	b.WriteString(`"bottom"`)
+	b.WriteString(strings.Repeat("}", depth))
+	return []byte(b.String()), keys
+}
+
+// An array of n objects.
+func arrayDocument(n int) []byte {
+	var b strings.Builder
+	b.WriteString(`{"list": [`)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `{"id": %d, "name": "item %d", "tags": ["a", "b"], "a": 1, "b": 2, "c": 3}`, i, i)
+	}
+	b.WriteString(`]}`)
+	return []byte(b.String())
+}
+
+func benchmarkObject(b *testing.B, doc []byte) *Object {
+	o, err := NewObjectFromBytes(doc)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	return o
+}
+
+func BenchmarkGetPathWide(b *testing.B) {
+	o := benchmarkObject(b, wideDocument(1000))
+	for i := 0; i < b.N; i++ {
+		if s, err := o.GetString("a", "b", "c"); err != nil || s != "found" {
+			b.Fatal(s, err)
+		}
+	}
+}
+
+func BenchmarkPositionsWide(b *testing.B) {
+	o, _ := benchmarkObject(b, wideDocument(20000)).GetObject("a")
+	for i := 0; i < b.N; i++ {
+		for _, child := range o.Map() {
+			if !child.Position().IsValid() || !child.KeyPosition().IsValid() {
+				b.Fatal("no position")
+			}
+		}
+	}
+}
+
+func BenchmarkMarshalWide(b *testing.B) {
+	o, _ := benchmarkObject(b, wideDocument(20000)).GetObject("a")
+	for i := 0; i < b.N; i++ {
+		if _, err := o.MarshalJSON(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetPathDeep(b *testing.B) {
+	doc, keys := deepDocument(50)
+	o := benchmarkObject(b, doc)
+	for i := 0; i < b.N; i++ {
+		if s, err := o.GetString(keys...); err != nil || s != "bottom" {
+			b.Fatal(s, err)
+		}
+	}
+}
+
+func BenchmarkGetObjectArray(b *testing.B) {
+	o := benchmarkObject(b, arrayDocument(1000))
+	for i := 0; i < b.N; i++ {
+		if objects, err := o.GetObjectArray("list"); err != nil || len(objects) != 1000 {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetObjectArrayFields(b *testing.B) {
+	o := benchmarkObject(b, arrayDocument(1000))
+	for i := 0; i < b.N; i++ {
+		objects, _ := o.GetObjectArray("list")
+		for _, object := range objects {
+			if _, err := object.GetInt64("id"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkArray(b *testing.B) {
+	o := benchmarkObject(b, arrayDocument(1000))
+	list, _ := o.GetValue("list")
+	for i := 0; i < b.N; i++ {
+		if values, err := list.Array(); err != nil || len(values) != 1000 {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkValueGetChain(b *testing.B) {
+	doc, keys := deepDocument(50)
+	v, _ := NewValueFromBytes(doc)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		current := v
+		for _, key := range keys {
+			current = current.Get(key)
+		}
+		if s, err := current.String(); err != nil || s != "bottom" {
+			b.Fatal(s, err)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	doc := arrayDocument(1000)
+	b.SetBytes(int64(len(doc)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewObjectFromBytes(doc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseLazy(b *testing.B) {
+	doc := arrayDocument(1000)
+	b.SetBytes(int64(len(doc)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		v, err := NewLazyValue(doc)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := v.Get("list").Get(-1).Get("id").Int64(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type benchmarkItem struct {
+	ID   int      `json:"id"`
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+func BenchmarkDecode(b *testing.B) {
+	o := benchmarkObject(b, arrayDocument(1000))
+	for i := 0; i < b.N; i++ {
+		var items []benchmarkItem
+		if err := o.GetInto(&items, "list"); err != nil || len(items) != 1000 {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeMarshalUnmarshal(b *testing.B) {
+	o := benchmarkObject(b, arrayDocument(1000))
+	for i := 0; i < b.N; i++ {
+		list, _ := o.GetValue("list")
+		data, err := list.Marshal()
+		if err != nil {
+			b.Fatal(err)
+		}
+		var items []benchmarkItem
+		if err := json.Unmarshal(data, &items); err != nil || len(items) != 1000 {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package jason
+
+import (
+	"encoding/json"
+)
+
+// ObjectBuilder builds a JSON object with chained calls.
+// The first error, e.g. an unsupported number type, is reported by Object() and Value().
+// Example:
+//
+//	o, err := jason.NewObjectBuilder().
+//		Str("name", "Walter White").
+//		Num("age", 51).
+//		Obj("other", func(b *jason.ObjectBuilder) {
+//			b.Str("occupation", "chemist")
+//		}).
+//		Arr("children", func(b *jason.ArrayBuilder) {
+//			b.Str("junior").Str("holly")
+//		}).
+//		Object()
+type ObjectBuilder struct {
+	m   map[string]interface{}
+	err error
+}
+
+// ArrayBuilder builds a JSON array with chained calls. See ObjectBuilder.
+type ArrayBuilder struct {
+	s   []interface{}
+	err error
+}
+
+// Creates a builder for an empty object.
+func NewObjectBuilder() *ObjectBuilder {
+	return &ObjectBuilder{m: map[string]interface{}{}}
+}
+
+// Creates a builder for an empty array.
+func NewArrayBuilder() *ArrayBuilder {
+	return &ArrayBuilder{s: []interface{}{}}
+}
+
+// Sets key to a string.
+func (b *ObjectBuilder) Str(key, s string) *ObjectBuilder {
+	b.m[key] = s
+	return b
+}
+
+// Sets key to a number. n may be any Go integer or float type, or a json.Number.
+func (b *ObjectBuilder) Num(key string, n interface{}) *ObjectBuilder {
+	data, err := numberData(n)
+	b.set(key, data, err)
+	return b
+}
+
+// Sets key to a bool.
+func (b *ObjectBuilder) Bool(key string, v bool) *ObjectBuilder {
+	b.m[key] = v
+	return b
+}
+
+// Sets key to null.
+func (b *ObjectBuilder) Null(key string) *ObjectBuilder {
+	b.m[key] = nil
+	return b
+}
+
+// Sets key to an object built by fn.
+func (b *ObjectBuilder) Obj(key string, fn func(b *ObjectBuilder)) *ObjectBuilder {
+	child := NewObjectBuilder()
+	fn(child)
+	b.set(key, child.m, child.err)
+	return b
+}
+
+// Sets key to an array built by fn.
+func (b *ObjectBuilder) Arr(key string, fn func(b *ArrayBuilder)) *ObjectBuilder {
+	child := NewArrayBuilder()
+	fn(child)
+	b.set(key, child.s, child.err)
+	return b
+}
+
+// Sets key to a copy of v. v may be anything accepted by Value.Set.
+func (b *ObjectBuilder) Val(key string, v interface{}) *ObjectBuilder {
+	data, err := toData(v)
+	b.set(key, data, err)
+	return b
+}
+
+func (b *ObjectBuilder) set(key string, data interface{}, err error) {
+	if err != nil {
+		if b.err == nil {
+			b.err = err
+		}
+		return
+	}
+	b.m[key] = data
+}
+
+// Returns the object built so far.
+// The builder can be used again afterwards without affecting the result.
+func (b *ObjectBuilder) Object() (*Object, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	v := &Value{data: copyData(b.m), exists: true}
+	return v.Object()
+}
+
+// Returns the object built so far as a Value.
+func (b *ObjectBuilder) Value() (*Value, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return &Value{data: copyData(b.m), exists: true}, nil
+}
+
+// Appends a string.
+func (b *ArrayBuilder) Str(s string) *ArrayBuilder {
+	b.s = append(b.s, s)
+	return b
+}
+
+// Appends a number. n may be any Go integer or float type, or a json.Number.
+func (b *ArrayBuilder) Num(n interface{}) *ArrayBuilder {
+	data, err := numberData(n)
+	b.append(data, err)
+	return b
+}
+
+// Appends a bool.
+func (b *ArrayBuilder) Bool(v bool) *ArrayBuilder {
+	b.s = append(b.s, v)
+	return b
+}
+
+// Appends null.
+func (b *ArrayBuilder) Null() *ArrayBuilder {
+	b.s = append(b.s, nil)
+	return b
+}
+
+// Appends an object built by fn.
+func (b *ArrayBuilder) Obj(fn func(b *ObjectBuilder)) *ArrayBuilder {
+	child := NewObjectBuilder()
+	fn(child)
+	b.append(child.m, child.err)
+	return b
+}
+
+// Appends an array built by fn.
+func (b *ArrayBuilder) Arr(fn func(b *ArrayBuilder)) *ArrayBuilder {
+	child := NewArrayBuilder()
+	fn(child)
+	b.append(child.s, child.err)
+	return b
+}
+
+// Appends a copy of v. v may be anything accepted by Value.Set.
+func (b *ArrayBuilder) Val(v interface{}) *ArrayBuilder {
+	data, err := toData(v)
+	b.append(data, err)
+	return b
+}
+
+func (b *ArrayBuilder) append(data interface{}, err error) {
+	if err != nil {
+		if b.err == nil {
+			b.err = err
+		}
+		return
+	}
+	b.s = append(b.s, data)
+}
+
+// Returns the array built so far as a Value.
+// The builder can be used again afterwards without affecting the result.
+func (b *ArrayBuilder) Value() (*Value, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return &Value{data: copyData(b.s), exists: true}, nil
+}
+
+func numberData(n interface{}) (interface{}, error) {
+	data, err := toData(n)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := data.(json.Number); !ok {
+		return nil, ErrNotNumber
+	}
+	return data, nil
+}
@@ -0,0 +1,119 @@
+package jason
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestObjectBuilder(t *testing.T) {
+	b := NewObjectBuilder().
+		Str("name", "Walter White").
+		Num("age", 51).
+		Num("height", 1.8).
+		Num("id", json.Number("12345678901234567890")).
+		Bool("married", true).
+		Null("nickname").
+		Obj("other", func(b *ObjectBuilder) {
+			b.Str("occupation", "chemist").Num("years", 23)
+		}).
+		Arr("children", func(b *ArrayBuilder) {
+			b.Str("junior").Str("holly")
+		}).
+		Arr("mixed", func(b *ArrayBuilder) {
+			b.Num(1).Bool(false).Null().
+				Obj(func(b *ObjectBuilder) { b.Str("a", "b") }).
+				Arr(func(b *ArrayBuilder) { b.Num(uint8(2)) })
+		})
+
+	o, err := b.Object()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name, err := o.GetString("name"); err != nil || name != "Walter White" {
+		t.Error(name, err)
+	}
+	if age, err := o.GetInt64("age"); err != nil || age != 51 {
+		t.Error(age, err)
+	}
+	if height, err := o.GetFloat64("height"); err != nil || height != 1.8 {
+		t.Error(height, err)
+	}
+	if married, err := o.GetBoolean("married"); err != nil || !married {
+		t.Error(married, err)
+	}
+	if err := o.GetNull("nickname"); err != nil {
+		t.Error(err)
+	}
+	if occupation, err := o.GetString("other", "occupation"); err != nil || occupation != "chemist" {
+		t.Error(occupation, err)
+	}
+	if children, err := o.GetStringArray("children"); err != nil || len(children) != 2 {
+		t.Error(children, err)
+	}
+
+	out, err := o.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"age":51,"children":["junior","holly"],"height":1.8,"id":12345678901234567890,"married":true,` +
+		`"mixed":[1,false,null,{"a":"b"},[2]],"name":"Walter White","nickname":null,"other":{"occupation":"chemist","years":23}}`
+	if string(out) != want {
+		t.Errorf("got %s", out)
+	}
+
+	// Later builder calls don't change objects that were already built.
+	b.Str("name", "Heisenberg")
+	if name, _ := o.GetString("name"); name != "Walter White" {
+		t.Error(name)
+	}
+}
+
+func TestBuilderValues(t *testing.T) {
+	source, err := NewObjectFromBytes([]byte(`{"street": "Street 42"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := NewArrayBuilder().Val(source).Val([]int{1, 2}).Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if street, err := v.Pointer("/0/street"); err != nil {
+		t.Error(err)
+	} else if s, _ := street.String(); s != "Street 42" {
+		t.Error(s)
+	}
+	if b, _ := v.Marshal(); string(b) != `[{"street":"Street 42"},[1,2]]` {
+		t.Errorf("got %s", b)
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	if _, err := NewObjectBuilder().Num("n", "1").Object(); err != ErrNotNumber {
+		t.Error(err)
+	}
+	if _, err := NewObjectBuilder().Arr("a", func(b *ArrayBuilder) { b.Num(json.Number("x")) }).Value(); err == nil {
+		t.Error("expected an error")
+	}
+	if _, err := NewArrayBuilder().Obj(func(b *ObjectBuilder) { b.Val("f", func() {}) }).Value(); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestBuilderFloats(t *testing.T) {
+	b := NewObjectBuilder().Num("n", 2e6).Num("small", float32(1e-7)).
+		Arr("list", func(b *ArrayBuilder) { b.Num(1e21).Num(0.25) })
+	v, err := b.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := v.Marshal()
+	want, _ := json.Marshal(map[string]interface{}{"n": 2e6, "small": float32(1e-7), "list": []interface{}{1e21, 0.25}})
+	if string(got) != string(want) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if n, err := v.Get("n").Int64(); err != nil || n != 2000000 {
+		t.Errorf("Int64() = %d, %v", n, err)
+	}
+}
@@ -0,0 +1,136 @@
+package jason
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// How the accessors of a value treat values of the wrong type, see Lenient.
+type coercion int8
+
+const (
+	coerceInherit coercion = iota // Like the value it was read from
+	coerceLenient
+	coerceStrict
+)
+
+// Returns a view of the value whose accessors, and those of every value read from it, accept
+// loosely typed data: Number, Int64 and Float64 accept numeric strings like "42", Boolean accepts
+// strings like "true" and the numbers 1 and 0, String accepts numbers and booleans, and Array
+// accepts a single value as an array of one element. The view shares the data of v.
+// Example:
+//
+//	id, err := v.Lenient().Get("id").Int64() // 42 for {"id": "42"}
+func (v *Value) Lenient() *Value {
+	return v.withCoercion(coerceLenient)
+}
+
+// Returns a view of the value that doesn't coerce, undoing Lenient for it and the values read from it.
+// Example:
+//
+//	total, err := lenient.Get("total").Strict().Int64()
+func (v *Value) Strict() *Value {
+	return v.withCoercion(coerceStrict)
+}
+
+// Returns a view of the object whose getters accept loosely typed data, see Value.Lenient.
+// Keep the view to make every lookup lenient, or use it for a single call.
+// Example:
+//
+//	api := o.Lenient()
+//	count, err := api.GetInt64("count")          // 3 for {"count": "3"}
+//	tags, err := api.GetStringArray("tags")      // ["a"] for {"tags": "a"}
+//	enabled, err := o.Lenient().GetBoolean("on") // true for {"on": 1}
+func (v *Object) Lenient() *Object {
+	return v.withCoercion(coerceLenient)
+}
+
+// Returns a view of the object that doesn't coerce, see Value.Strict.
+func (v *Object) Strict() *Object {
+	return v.withCoercion(coerceStrict)
+}
+
+func (v *Value) withCoercion(c coercion) *Value {
+	if v == nil {
+		return nil
+	}
+	view := *v
+	view.coerce = c
+	return &view
+}
+
+func (v *Object) withCoercion(c coercion) *Object {
+	if v == nil {
+		return nil
+	}
+	view := &Object{Value: v.Value, valid: v.valid}
+	view.coerce = c
+	view.object = view
+	return view
+}
+
+// Reports whether the accessors of v coerce, which is decided by the nearest value on the way
+// from the root that has Lenient or Strict set.
+func (v *Value) isLenient() bool {
+	for current := v; current != nil; current = current.parent {
+		if current.coerce != coerceInherit {
+			return current.coerce == coerceLenient
+		}
+	}
+	return false
+}
+
+// Converts a numeric string for Number.
+func (v *Value) coerceNumber() (json.Number, bool) {
+	s, ok := v.data.(string)
+	if !ok || !v.isLenient() {
+		return "", false
+	}
+	s = strings.TrimSpace(s)
+	return json.Number(s), isNumber(s)
+}
+
+// Converts strings like "true" and the numbers 1 and 0 for Boolean.
+func (v *Value) coerceBoolean() (bool, bool) {
+	if !v.isLenient() {
+		return false, false
+	}
+	switch data := v.data.(type) {
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(data))
+		return b, err == nil
+	case json.Number:
+		f, err := data.Float64()
+		return f == 1, err == nil && (f == 0 || f == 1)
+	}
+	return false, false
+}
+
+// Converts numbers and booleans for String.
+func (v *Value) coerceString() (string, bool) {
+	if !v.isLenient() {
+		return "", false
+	}
+	switch data := v.data.(type) {
+	case json.Number:
+		return string(data), true
+	case bool:
+		return strconv.FormatBool(data), true
+	}
+	return "", false
+}
+
+// Reports whether Array may return the value as an array of one element.
+func (v *Value) coerceArray() bool {
+	return v.data != nil && v.isLenient()
+}
+
+// Converts a single object for ObjectArray.
+func (v *Value) coerceObjectArray() ([]*Object, bool) {
+	if _, ok := v.data.(map[string]interface{}); !ok || !v.isLenient() {
+		return nil, false
+	}
+	o, err := v.Object()
+	return []*Object{o}, err == nil
+}
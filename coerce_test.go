@@ -0,0 +1,114 @@
+package jason
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const looseDoc = `{
+	"id": "42", "price": " 1.5 ", "count": 3, "on": "true", "off": 0, "flag": 2,
+	"name": 7, "yes": true, "tag": "a", "tags": ["a", 1], "ids": ["1", 2],
+	"friend": {"name": "dave", "age": "30"}, "nothing": null, "word": "abc"
+}`
+
+func TestLenient(t *testing.T) {
+	o, err := NewObjectFromBytes([]byte(looseDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := o.Lenient()
+
+	if id, err := l.GetInt64("id"); err != nil || id != 42 {
+		t.Errorf("GetInt64 = %d, %v", id, err)
+	}
+	if f, err := l.GetFloat64("price"); err != nil || f != 1.5 {
+		t.Errorf("GetFloat64 = %v, %v", f, err)
+	}
+	if n, err := l.GetNumber("count"); err != nil || n != "3" {
+		t.Errorf("GetNumber = %v, %v", n, err)
+	}
+	if b, err := l.GetBoolean("on"); err != nil || !b {
+		t.Errorf("GetBoolean string = %v, %v", b, err)
+	}
+	if b, err := l.GetBoolean("off"); err != nil || b {
+		t.Errorf("GetBoolean number = %v, %v", b, err)
+	}
+	if s, err := l.GetString("name"); err != nil || s != "7" {
+		t.Errorf("GetString number = %q, %v", s, err)
+	}
+	if s, err := l.GetString("yes"); err != nil || s != "true" {
+		t.Errorf("GetString bool = %q, %v", s, err)
+	}
+	if s, err := l.GetStringArray("tag"); err != nil || !reflect.DeepEqual(s, []string{"a"}) {
+		t.Errorf("GetStringArray single = %v, %v", s, err)
+	}
+	if s, err := l.GetStringArray("tags"); err != nil || !reflect.DeepEqual(s, []string{"a", "1"}) {
+		t.Errorf("GetStringArray = %v, %v", s, err)
+	}
+	if s, err := l.GetInt64Array("ids"); err != nil || !reflect.DeepEqual(s, []int64{1, 2}) {
+		t.Errorf("GetInt64Array = %v, %v", s, err)
+	}
+	if s, err := l.GetObjectArray("friend"); err != nil || len(s) != 1 {
+		t.Errorf("GetObjectArray single = %v, %v", s, err)
+	}
+	if age, err := l.GetInt64("friend", "age"); err != nil || age != 30 {
+		t.Errorf("nested GetInt64 = %d, %v", age, err)
+	}
+	if friend, err := l.GetObject("friend"); err != nil {
+		t.Error(err)
+	} else if age, err := friend.GetInt64("age"); err != nil || age != 30 {
+		t.Errorf("GetInt64 of a child object = %d, %v", age, err)
+	}
+	if age, err := Get[uint8](l.Value.Get("friend"), "age"); err != nil || age != 30 {
+		t.Errorf("Get = %d, %v", age, err)
+	}
+	if ptr, err := l.Pointer("/id"); err != nil {
+		t.Error(err)
+	} else if id, err := ptr.Int64(); err != nil || id != 42 {
+		t.Errorf("Pointer Int64 = %d, %v", id, err)
+	}
+}
+
+func TestLenientErrors(t *testing.T) {
+	o, _ := NewObjectFromBytes([]byte(looseDoc))
+	l := o.Lenient()
+
+	cases := []struct {
+		name string
+		get  func() error
+		want error
+	}{
+		{"word as number", func() error { _, err := l.GetInt64("word"); return err }, ErrNotNumber},
+		{"2 as bool", func() error { _, err := l.GetBoolean("flag"); return err }, ErrNotBool},
+		{"object as string", func() error { _, err := l.GetString("friend"); return err }, ErrNotString},
+		{"null as array", func() error { _, err := l.GetStringArray("nothing"); return err }, ErrNotArray},
+		{"strict again", func() error { _, err := l.Strict().GetInt64("id"); return err }, ErrNotNumber},
+		{"strict child", func() error { _, err := l.Value.Get("friend").Strict().Get("age").Int64(); return err }, ErrNotNumber},
+		{"original", func() error { _, err := o.GetInt64("id"); return err }, ErrNotNumber},
+	}
+	for _, c := range cases {
+		if err := c.get(); !errors.Is(err, c.want) {
+			t.Errorf("%s: error = %v, want %v", c.name, err, c.want)
+		}
+	}
+}
+
+func TestLenientLazy(t *testing.T) {
+	v, err := NewLazyValue([]byte(looseDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := v.Lenient()
+	if id, err := l.Get("id").Int64(); err != nil || id != 42 {
+		t.Errorf("Int64 = %d, %v", id, err)
+	}
+	if tags, err := GetArray[string](l, "tag"); err != nil || !reflect.DeepEqual(tags, []string{"a"}) {
+		t.Errorf("GetArray = %v, %v", tags, err)
+	}
+	if o, err := l.Get("friend").Object(); err != nil {
+		t.Error(err)
+	} else if age, err := o.GetInt64("age"); err != nil || age != 30 {
+		t.Errorf("GetInt64 = %d, %v", age, err)
+	}
+}
@@ -0,0 +1,536 @@
+package jason
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Decodes the value into dst, which must be a non-nil pointer, like json.Unmarshal does.
+// Struct fields are matched by their json tags or names like encoding/json, ignoring case when no name
+// matches exactly. Members without a field are ignored. Numbers in an empty interface are float64;
+// decode into json.Number or *Value to keep them exact. Fields of type *Value and *Object get the value
+// itself, and types whose pointer implements Unmarshaler, json.Unmarshaler or encoding.TextUnmarshaler
+// decode themselves.
+// Errors are *PathError with the path from the document root.
+// Example:
+//
+//	var address Address
+//	err := v.Get("person").Get("address").Decode(&address)
+func (v *Value) Decode(dst interface{}) error {
+	if v.Err != nil {
+		return v.Err
+	}
+	if err := v.load(); err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &json.InvalidUnmarshalError{Type: reflect.TypeOf(dst)}
+	}
+	return decodeValue(v, rv.Elem(), false)
+}
+
+// Gets the value at key path and decodes it into dst. See Value.Decode.
+// Example:
+//
+//	var address Address
+//	err := o.GetInto(&address, "person", "address")
+func (v *Object) GetInto(dst interface{}, keys ...string) error {
+	child, err := v.getPath(keys)
+	if err != nil {
+		return err
+	}
+	return child.Decode(dst)
+}
+
+var (
+	valueType           = reflect.TypeOf((*Value)(nil))
+	objectType          = reflect.TypeOf((*Object)(nil))
+	numberType          = reflect.TypeOf(json.Number(""))
+	unmarshalerType     = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Decodes v into rv, which must be settable. quoted is set for fields with the ",string" option.
+func decodeValue(v *Value, rv reflect.Value, quoted bool) error {
+	data := v.raw()
+
+	switch rv.Type() {
+	case valueType:
+		rv.Set(reflect.ValueOf(v))
+		return nil
+	case objectType:
+		if data == nil {
+			rv.Set(reflect.Zero(objectType))
+			return nil
+		}
+		o, err := v.Object()
+		if err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(o))
+		return nil
+	}
+
+	if data == nil {
+		switch rv.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+			rv.Set(reflect.Zero(rv.Type()))
+		}
+		// Like encoding/json, null leaves anything else unchanged
+		return nil
+	}
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return decodeValue(v, rv.Elem(), quoted)
+	}
+
+	if rv.CanAddr() {
+		if handled, err := decodeUnmarshaler(v, rv.Addr()); handled {
+			return err
+		}
+	}
+	if quoted {
+		unquoted, err := unquote(v, rv.Kind())
+		if err != nil {
+			return err
+		}
+		v, data = unquoted, unquoted.data
+	}
+
+	switch rv.Kind() {
+	case reflect.Interface:
+		if rv.NumMethod() > 0 {
+			break
+		}
+		data, err := interfaceData(v, data)
+		if err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(data))
+		return nil
+	case reflect.Bool:
+		b, ok := data.(bool)
+		if !ok {
+			return v.typeError(KindBool, ErrNotBool)
+		}
+		rv.SetBool(b)
+		return nil
+	case reflect.String:
+		if rv.Type() == numberType {
+			n, err := v.Number()
+			if err != nil {
+				return err
+			}
+			rv.SetString(string(n))
+			return nil
+		}
+		s, ok := data.(string)
+		if !ok {
+			return v.typeError(KindString, ErrNotString)
+		}
+		rv.SetString(s)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := v.Number()
+		if err != nil {
+			return err
+		}
+		i, err := strconv.ParseInt(string(n), 10, rv.Type().Bits())
+		if err != nil {
+			return v.typeError(KindNumber, err)
+		}
+		rv.SetInt(i)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := v.Number()
+		if err != nil {
+			return err
+		}
+		i, err := strconv.ParseUint(string(n), 10, rv.Type().Bits())
+		if err != nil {
+			return v.typeError(KindNumber, err)
+		}
+		rv.SetUint(i)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		n, err := v.Number()
+		if err != nil {
+			return err
+		}
+		f, err := strconv.ParseFloat(string(n), rv.Type().Bits())
+		if err != nil {
+			return v.typeError(KindNumber, err)
+		}
+		rv.SetFloat(f)
+		return nil
+	case reflect.Slice:
+		if s, ok := data.(string); ok && rv.Type().Elem().Kind() == reflect.Uint8 {
+			// Like encoding/json, byte slices are base64 strings
+			b, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return v.valueError(err)
+			}
+			rv.SetBytes(b)
+			return nil
+		}
+		s, ok := data.([]interface{})
+		if !ok {
+			return v.typeError(KindArray, ErrNotArray)
+		}
+		rv.Set(reflect.MakeSlice(rv.Type(), len(s), len(s)))
+		return decodeElements(v, rv, s)
+	case reflect.Array:
+		s, ok := data.([]interface{})
+		if !ok {
+			return v.typeError(KindArray, ErrNotArray)
+		}
+		if len(s) > rv.Len() {
+			s = s[:rv.Len()]
+		}
+		for i := len(s); i < rv.Len(); i++ {
+			rv.Index(i).Set(reflect.Zero(rv.Type().Elem()))
+		}
+		return decodeElements(v, rv, s)
+	case reflect.Map:
+		m, ok := data.(map[string]interface{})
+		if !ok {
+			return v.typeError(KindObject, ErrNotObject)
+		}
+		return decodeMap(v, rv, m)
+	case reflect.Struct:
+		m, ok := data.(map[string]interface{})
+		if !ok {
+			return v.typeError(KindObject, ErrNotObject)
+		}
+		return decodeStruct(v, rv, m)
+	}
+	return v.valueError(fmt.Errorf("%w %s", ErrUnsupportedType, rv.Type()))
+}
+
+// Copies data for an empty interface, with the numbers converted to float64 like json.Unmarshal does.
+func interfaceData(v *Value, data interface{}) (interface{}, error) {
+	switch data := data.(type) {
+	case json.Number:
+		f, err := strconv.ParseFloat(string(data), 64)
+		if err != nil {
+			return nil, v.typeError(KindNumber, err)
+		}
+		return f, nil
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(data))
+		for key, element := range data {
+			converted, err := interfaceData(v.child(key, element, true), element)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = converted
+		}
+		return m, nil
+	case []interface{}:
+		s := make([]interface{}, len(data))
+		for i, element := range data {
+			converted, err := interfaceData(v.child(strconv.Itoa(i), element, true), element)
+			if err != nil {
+				return nil, err
+			}
+			s[i] = converted
+		}
+		return s, nil
+	}
+	return data, nil
+}
+
+// Lets a type decode itself. Reports whether ptr implements one of the unmarshal interfaces.
+func decodeUnmarshaler(v *Value, ptr reflect.Value) (bool, error) {
+	var err error
+	switch {
+	case ptr.Type().Implements(unmarshalerType):
+		err = ptr.Interface().(Unmarshaler).UnmarshalJason(v)
+	case ptr.Type().Implements(jsonUnmarshalerType):
+		var b []byte
+		if b, err = json.Marshal(v.raw()); err == nil {
+			err = ptr.Interface().(json.Unmarshaler).UnmarshalJSON(b)
+		}
+	case ptr.Type().Implements(textUnmarshalerType):
+		s, ok := v.raw().(string)
+		if !ok {
+			return true, v.typeError(KindString, ErrNotString)
+		}
+		err = ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	default:
+		return false, nil
+	}
+	if err != nil {
+		return true, v.valueError(err)
+	}
+	return true, nil
+}
+
+// Reads the value of a field with the ",string" option from its string.
+func unquote(v *Value, kind reflect.Kind) (*Value, error) {
+	s, ok := v.raw().(string)
+	if !ok {
+		return nil, v.typeError(KindString, ErrNotString)
+	}
+	var data interface{}
+	switch kind {
+	case reflect.String:
+		var unquoted string
+		if err := json.Unmarshal([]byte(s), &unquoted); err != nil {
+			return nil, v.valueError(err)
+		}
+		data = unquoted
+	case reflect.Bool:
+		if s != "true" && s != "false" {
+			return nil, v.valueError(fmt.Errorf("invalid bool %q", s))
+		}
+		data = s == "true"
+	default:
+		if !isNumber(s) {
+			return nil, v.valueError(fmt.Errorf("invalid number literal %q", s))
+		}
+		data = json.Number(s)
+	}
+	return &Value{data: data, exists: true, parent: v.parent, key: v.key}, nil
+}
+
+func decodeElements(v *Value, rv reflect.Value, s []interface{}) error {
+	for i, element := range s {
+		if err := decodeValue(v.child(strconv.Itoa(i), element, true), rv.Index(i), false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeMap(v *Value, rv reflect.Value, m map[string]interface{}) error {
+	t := rv.Type()
+	if rv.IsNil() {
+		rv.Set(reflect.MakeMapWithSize(t, len(m)))
+	}
+	for _, key := range orderedKeys(m, v.sourceNode()) {
+		child := v.child(key, m[key], true)
+
+		k := reflect.New(t.Key()).Elem()
+		switch {
+		case reflect.PtrTo(t.Key()).Implements(textUnmarshalerType):
+			if err := k.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(key)); err != nil {
+				return child.valueError(err)
+			}
+		case t.Key().Kind() == reflect.String:
+			k.SetString(key)
+		case k.CanInt():
+			i, err := strconv.ParseInt(key, 10, t.Key().Bits())
+			if err != nil {
+				return child.valueError(err)
+			}
+			k.SetInt(i)
+		case k.CanUint():
+			i, err := strconv.ParseUint(key, 10, t.Key().Bits())
+			if err != nil {
+				return child.valueError(err)
+			}
+			k.SetUint(i)
+		default:
+			return v.valueError(fmt.Errorf("%w %s", ErrUnsupportedType, t))
+		}
+
+		element := reflect.New(t.Elem()).Elem()
+		if err := decodeValue(child, element, false); err != nil {
+			return err
+		}
+		rv.SetMapIndex(k, element)
+	}
+	return nil
+}
+
+func decodeStruct(v *Value, rv reflect.Value, m map[string]interface{}) error {
+	fields := structFields(rv.Type())
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	matches, ambiguous := matchFields(fields, keys)
+	if ambiguous {
+		// The last of the members in the document wins, like in encoding/json
+		keys = orderedKeys(m, v.sourceNode())
+		matches, _ = matchFields(fields, keys)
+	}
+	for i, f := range fields {
+		if matches[i] < 0 {
+			continue
+		}
+		key := keys[matches[i]]
+		field, err := fieldByIndex(rv, f.index)
+		if err != nil {
+			return v.valueError(err)
+		}
+		if err := decodeValue(v.child(key, m[key], true), field, f.quoted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Matches members to fields like encoding/json: a member goes to the field of its name, or else to the
+// first field whose name matches ignoring case. Returns the index in keys of the last member of each field,
+// or -1, and whether a field matched several members.
+func matchFields(fields []structField, keys []string) ([]int, bool) {
+	matches := make([]int, len(fields))
+	for i := range matches {
+		matches[i] = -1
+	}
+	ambiguous := false
+	for k, key := range keys {
+		if i := fieldFor(fields, key); i >= 0 {
+			ambiguous = ambiguous || matches[i] >= 0
+			matches[i] = k
+		}
+	}
+	return matches, ambiguous
+}
+
+func fieldFor(fields []structField, key string) int {
+	for i, f := range fields {
+		if f.name == key {
+			return i
+		}
+	}
+	for i, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// A struct field that members are decoded into or encoded from.
+type structField struct {
+	name      string
+	index     []int
+	tagged    bool
+	quoted    bool
+	omitEmpty bool
+}
+
+var fieldCache sync.Map // reflect.Type to []structField
+
+// Returns the fields of a struct type, including those promoted from embedded structs, like encoding/json.
+// A shallower field hides deeper ones of the same name. Of the fields with the same name and depth,
+// a single tagged one wins, and otherwise none is used.
+func structFields(t reflect.Type) []structField {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.([]structField)
+	}
+
+	type embedded struct {
+		t     reflect.Type
+		index []int
+	}
+	var fields []structField
+	names := make(map[string]bool)
+	visited := make(map[reflect.Type]bool)
+	for current := []embedded{{t, nil}}; len(current) > 0; {
+		var next []embedded
+		var level []structField
+		for _, e := range current {
+			if visited[e.t] {
+				continue
+			}
+			visited[e.t] = true
+			for i := 0; i < e.t.NumField(); i++ {
+				sf := e.t.Field(i)
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, options, _ := strings.Cut(tag, ",")
+				index := append(e.index[:len(e.index):len(e.index)], i)
+				if sf.Anonymous && name == "" {
+					ft := sf.Type
+					if ft.Kind() == reflect.Ptr {
+						ft = ft.Elem()
+					}
+					if ft.Kind() == reflect.Struct && (sf.IsExported() || sf.Type.Kind() != reflect.Ptr) {
+						next = append(next, embedded{ft, index})
+						continue
+					}
+				}
+				if !sf.IsExported() {
+					continue
+				}
+				tagged := name != ""
+				if !tagged {
+					name = sf.Name
+				}
+				f := structField{name: name, index: index, tagged: tagged}
+				// Options can be combined, e.g. ",omitempty,string"
+				for _, option := range strings.Split(options, ",") {
+					f.quoted = f.quoted || option == "string"
+					f.omitEmpty = f.omitEmpty || option == "omitempty"
+				}
+				level = append(level, f)
+			}
+		}
+		for _, f := range level {
+			if !names[f.name] {
+				names[f.name] = true
+				if dominant, ok := dominantField(level, f.name); ok {
+					fields = append(fields, dominant)
+				}
+			}
+		}
+		current = next
+	}
+
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+// Picks the field for a name among the fields of one depth, like encoding/json.
+func dominantField(level []structField, name string) (structField, bool) {
+	var untagged, tagged []structField
+	for _, f := range level {
+		switch {
+		case f.name != name:
+		case f.tagged:
+			tagged = append(tagged, f)
+		default:
+			untagged = append(untagged, f)
+		}
+	}
+	if len(tagged) == 1 {
+		return tagged[0], true
+	}
+	if len(tagged) == 0 && len(untagged) == 1 {
+		return untagged[0], true
+	}
+	return structField{}, false
+}
+
+// Returns the field at the index, allocating the embedded structs on the way.
+func fieldByIndex(rv reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				if !rv.CanSet() {
+					return reflect.Value{}, fmt.Errorf("can't set embedded pointer to unexported struct %s", rv.Type().Elem())
+				}
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, nil
+}
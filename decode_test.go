@@ -0,0 +1,209 @@
+package jason
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type decodeAddress struct {
+	Street string `json:"street"`
+	Zip    int    `json:"zip,string"`
+	hidden string
+}
+
+type decodeBase struct {
+	ID      uint64    `json:"id"`
+	Created time.Time `json:"created"`
+}
+
+type decodePerson struct {
+	decodeBase
+	*DecodeExtra
+	Name      string
+	Nick      *string          `json:"nick"`
+	Age       int8             `json:"age"`
+	Score     float32          `json:"score"`
+	Active    bool             `json:"active"`
+	Tags      []string         `json:"tags"`
+	Pair      [2]int           `json:"pair"`
+	Address   decodeAddress    `json:"address"`
+	Friends   []*decodeAddress `json:"friends"`
+	Counts    map[string]int   `json:"counts"`
+	ByID      map[int]string   `json:"by_id"`
+	IP        net.IP           `json:"ip"`
+	Temp      celsius          `json:"temp"`
+	Raw       *Value           `json:"raw"`
+	Meta      *Object          `json:"meta"`
+	Any       interface{}      `json:"any"`
+	Number    json.Number      `json:"number"`
+	Data      []byte           `json:"data"`
+	Ignored   string           `json:"-"`
+	Unchanged int              `json:"unchanged"`
+}
+
+type DecodeExtra struct {
+	Extra string `json:"extra"`
+}
+
+const personDoc = `{"person": {
+	"id": 7, "created": "2024-03-01T12:00:00Z", "extra": "x",
+	"NAME": "anton", "nick": "ant", "age": -5, "score": 1.5, "active": true,
+	"tags": ["a", "b"], "pair": [1, 2, 3],
+	"address": {"street": "Main", "zip": "12345", "hidden": "no"},
+	"friends": [{"street": "Side"}, null],
+	"counts": {"a": 1, "b": 2}, "by_id": {"1": "one"},
+	"ip": "127.0.0.1", "temp": "20C",
+	"raw": [1, {"deep": true}], "meta": {"k": "v"}, "any": {"list": [1, "two"]},
+	"number": 3.25, "data": "aGk=", "Ignored": "no", "unchanged": null, "unknown": 1
+}}`
+
+func TestDecode(t *testing.T) {
+	o, err := NewObjectFromBytes([]byte(personDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := decodePerson{Unchanged: 42}
+	if err := o.GetInto(&p, "person"); err != nil {
+		t.Fatal(err)
+	}
+
+	nick := "ant"
+	want := decodePerson{
+		decodeBase:  decodeBase{ID: 7, Created: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+		DecodeExtra: &DecodeExtra{Extra: "x"},
+		Name:        "anton", Nick: &nick, Age: -5, Score: 1.5, Active: true,
+		Tags: []string{"a", "b"}, Pair: [2]int{1, 2},
+		Address: decodeAddress{Street: "Main", Zip: 12345},
+		Friends: []*decodeAddress{{Street: "Side"}, nil},
+		Counts:  map[string]int{"a": 1, "b": 2}, ByID: map[int]string{1: "one"},
+		IP: net.ParseIP("127.0.0.1"), Temp: 20,
+		Any:    map[string]interface{}{"list": []interface{}{1.0, "two"}},
+		Number: "3.25", Data: []byte("hi"), Unchanged: 42,
+	}
+	raw, meta := p.Raw, p.Meta
+	p.Raw, p.Meta = nil, nil
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("got  %+v\nwant %+v", p, want)
+	}
+	if deep, err := raw.Get(1).Get("deep").Boolean(); err != nil || !deep || raw.Get(1).Position().Line != 9 {
+		t.Error(raw, err)
+	}
+	if k, _ := meta.GetString("k"); k != "v" {
+		t.Error(meta)
+	}
+
+	var tags []string
+	if err := o.Get("person").Get("tags").Decode(&tags); err != nil || len(tags) != 2 {
+		t.Error(tags, err)
+	}
+	var anything interface{}
+	if err := o.Get("person").Get("pair").Decode(&anything); err != nil || len(anything.([]interface{})) != 3 {
+		t.Error(anything, err)
+	}
+	lazy, _ := NewLazyValue([]byte(personDoc))
+	var address decodeAddress
+	if err := lazy.Get("person").Get("address").Decode(&address); err != nil || address.Zip != 12345 {
+		t.Error(address, err)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	cases := []struct {
+		member string
+		path   string
+		want   error
+	}{
+		{`"age": 300`, "/person/age", strconv.ErrRange},
+		{`"tags": ["a", 2]`, "/person/tags/1", ErrNotString},
+		{`"address": {"zip": "12a"}`, "/person/address/zip", nil},
+		{`"address": {"zip": 12}`, "/person/address/zip", ErrNotString},
+		{`"friends": [{"street": 1}]`, "/person/friends/0/street", ErrNotString},
+		{`"counts": {"a": "one"}`, "/person/counts/a", ErrNotNumber},
+		{`"by_id": {"x": "one"}`, "/person/by_id/x", strconv.ErrSyntax},
+		{`"ip": "nope"`, "/person/ip", nil},
+		{`"active": "yes"`, "/person/active", ErrNotBool},
+		{`"created": "soon"`, "/person/created", nil},
+		{`"temp": 20`, "/person/temp", ErrNotString},
+		{`"meta": []`, "/person/meta", ErrNotObject},
+		{`"data": "%%%"`, "/person/data", nil},
+		{`"pair": {}`, "/person/pair", ErrNotArray},
+		{`"any": {"a": [1e400]}`, "/person/any/a/0", strconv.ErrRange},
+	}
+	for _, c := range cases {
+		doc := "{\"person\": {\n  \"name\": \"anton\",\n  " + c.member + "}}"
+		v, err := NewValueFromBytes([]byte(doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var p decodePerson
+		err = v.Get("person").Decode(&p)
+		var e *PathError
+		if !errors.As(err, &e) || e.Path != c.path || e.Position.Line != 3 || (c.want != nil && !errors.Is(err, c.want)) {
+			t.Errorf("%s: got %v", c.member, err)
+		}
+	}
+
+	v, _ := NewValueFromBytes([]byte(`{"a": 1}`))
+	var notPointer decodePerson
+	var invalid *json.InvalidUnmarshalError
+	if err := v.Decode(notPointer); !errors.As(err, &invalid) {
+		t.Error(err)
+	}
+	var ch chan int
+	if err := v.Get("a").Decode(&ch); !errors.Is(err, ErrUnsupportedType) {
+		t.Error(err)
+	}
+	if err := v.Get("missing").Decode(&notPointer); !errors.As(err, new(*PathError)) {
+		t.Error(err)
+	}
+}
+
+type decodeTie struct {
+	X int
+	Y int
+}
+
+type decodeTagged struct {
+	X int
+	Y int `json:"Y"`
+}
+
+func TestDecodeLikeEncodingJSON(t *testing.T) {
+	type folded struct {
+		Name  string
+		Value int         `json:"value,omitempty,string"`
+		Any   interface{} `json:"any"`
+		decodeTie
+		decodeTagged
+	}
+	docs := []string{
+		`{"name": "a", "NAME": "b"}`,
+		`{"NAME": "b", "name": "a"}`,
+		`{"Name": "x", "name": "y"}`,
+		`{"name": "y", "Name": "x"}`,
+		`{"value": "12"}`,
+		`{"X": 1, "Y": 2}`,
+		`{"any": [1, {"x": 2.5, "y": [-3e2]}, "z", null]}`,
+	}
+	for _, doc := range docs {
+		v, err := NewValueFromBytes([]byte(doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got, want folded
+		if err := v.Decode(&got); err != nil {
+			t.Errorf("%s: %v", doc, err)
+		}
+		if err := json.Unmarshal([]byte(doc), &want); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %+v, want %+v", doc, got, want)
+		}
+	}
+}
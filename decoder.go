@@ -0,0 +1,156 @@
+package jason
+
+import (
+	"io"
+)
+
+// ParseOptions configures how JSON is parsed.
+// The zero value parses like NewValue.
+// Example:
+//
+//	v, err := jason.ParseOptions{Strict: true}.NewValue(res.Body)
+type ParseOptions struct {
+	// Rejects input with anything but whitespace after the value.
+	// By default everything after the first value is ignored.
+	Strict bool
+
+	// Accepts JSONC and JSON5: comments, trailing commas, single-quoted strings, unquoted member names,
+	// hexadecimal numbers, Infinity, NaN and leading plus signs. The values are the same as for JSON,
+	// e.g. 0x10 is read as the number 16. JSON has no numbers for Infinity and NaN, so they are
+	// read as null like JavaScript's JSON.stringify writes them: IsNull is true, Number and Float64
+	// return ErrNotNumber and Marshal writes null.
+	Relaxed bool
+
+	// Marshals objects with their members in document order instead of sorted by name.
+	// Members added later go last. See Object.Keys.
+	PreserveOrder bool
+
+	// Decides what happens when an object repeats a member name. By default the last value wins.
+	Duplicates DuplicatePolicy
+
+	// Restricts the size of the input. See Limits.
+	Limits Limits
+}
+
+func (o ParseOptions) newParser(r io.Reader) *parser {
+	p := newParser(r)
+	p.relaxed = o.Relaxed
+	p.ordered = o.PreserveOrder
+	p.duplicates = o.Duplicates
+	p.setLimits(o.Limits)
+	return p
+}
+
+func (o ParseOptions) newBytesParser(b []byte) *parser {
+	p := newBytesParser(b)
+	p.relaxed = o.Relaxed
+	p.ordered = o.PreserveOrder
+	p.duplicates = o.Duplicates
+	p.setLimits(o.Limits)
+	return p
+}
+
+func (o ParseOptions) parse(p *parser) (*Value, error) {
+	data, node, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	if o.Strict {
+		if err := p.end(); err != nil {
+			return nil, err
+		}
+	}
+	return &Value{data: data, node: node}, nil
+}
+
+// Creates a new value from a reader. See NewValue.
+func (o ParseOptions) NewValue(r io.Reader) (*Value, error) {
+	return o.parse(o.newParser(r))
+}
+
+// Creates a new value from bytes. See NewValueFromBytes.
+func (o ParseOptions) NewValueFromBytes(b []byte) (*Value, error) {
+	return o.parse(o.newBytesParser(b))
+}
+
+// Creates a new object from a reader. See NewObjectFromReader.
+func (o ParseOptions) NewObjectFromReader(r io.Reader) (*Object, error) {
+	return objectFromValue(o.NewValue(r))
+}
+
+// Creates a new object from bytes. See NewObjectFromBytes.
+func (o ParseOptions) NewObjectFromBytes(b []byte) (*Object, error) {
+	return objectFromValue(o.NewValueFromBytes(b))
+}
+
+// Creates a decoder for a stream of JSON values. See NewDecoder.
+func (o ParseOptions) NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{p: o.newParser(r)}
+}
+
+// Decoder reads a stream of concatenated JSON values, e.g. {"a":1}{"b":2} or one value per line.
+// Values may be separated by whitespace and by the record separators of JSON text sequences (RFC 7464).
+type Decoder struct {
+	p     *parser
+	value *Value
+	err   error
+}
+
+// Creates a decoder for a stream of JSON values.
+// Example:
+//
+//	d := jason.NewDecoder(res.Body)
+//	for d.Next() {
+//		name, err := d.Value().Get("name").String()
+//		...
+//	}
+//	if err := d.Err(); err != nil {
+//		...
+//	}
+func NewDecoder(r io.Reader) *Decoder {
+	return ParseOptions{}.NewDecoder(r)
+}
+
+// Advances to the next value. Returns false at the end of the stream or when an error occurred,
+// which is then reported by Err. Decoding stops at the first error.
+func (d *Decoder) Next() bool {
+	d.value = nil
+	if d.err != nil {
+		return false
+	}
+
+	d.p.skipRecordSeparators()
+	data, node, err := d.p.parse()
+	if err != nil {
+		if err != io.EOF {
+			d.err = err
+		}
+		return false
+	}
+	d.value = &Value{data: data, exists: true, node: node}
+	return true
+}
+
+// Returns the current value.
+func (d *Decoder) Value() *Value {
+	return d.value
+}
+
+// Returns the error that stopped the decoder, or nil at the end of the stream.
+func (d *Decoder) Err() error {
+	return d.err
+}
+
+// Reads all values of a stream of concatenated JSON values. See Decoder.
+// Returns the values read so far together with the first error.
+// Example:
+//
+//	values, err := jason.DecodeAll(strings.NewReader(`{"a":1} {"b":2}`))
+func DecodeAll(r io.Reader) ([]*Value, error) {
+	d := NewDecoder(r)
+	var values []*Value
+	for d.Next() {
+		values = append(values, d.Value())
+	}
+	return values, d.Err()
+}
@@ -0,0 +1,99 @@
+package jason
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStrict(t *testing.T) {
+	cases := []struct {
+		doc   string
+		valid bool
+	}{
+		{`{"a": 1}`, true},
+		{" [1] \n\t", true},
+		{`{"a":1}{"b":2}`, false},
+		{`[1] garbage`, false},
+		{`1 2`, false},
+		{`"a",`, false},
+	}
+	for _, c := range cases {
+		if _, err := NewValueFromBytes([]byte(c.doc)); err != nil {
+			t.Errorf("%s: %v", c.doc, err)
+		}
+		_, err := ParseOptions{Strict: true}.NewValueFromBytes([]byte(c.doc))
+		if c.valid && err != nil {
+			t.Errorf("%s: %v", c.doc, err)
+		}
+		var e *SyntaxError
+		if !c.valid && !errors.As(err, &e) {
+			t.Errorf("%s: expected a SyntaxError, got %v", c.doc, err)
+		}
+	}
+
+	if _, err := (ParseOptions{Strict: true}).NewObjectFromBytes([]byte(`{"a":1} x`)); err == nil {
+		t.Error("expected an error")
+	}
+	if _, err := (ParseOptions{Strict: true}).NewObjectFromReader(strings.NewReader(`{"a":1}`)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDecodeAll(t *testing.T) {
+	cases := []struct {
+		stream string
+		want   []string
+	}{
+		{`{"a":1}{"b":2}`, []string{`{"a":1}`, `{"b":2}`}},
+		{"1 2\n\"three\"[4]null", []string{`1`, `2`, `"three"`, `[4]`, `null`}},
+		{"\x1e{\"a\":1}\n\x1e[2]\n", []string{`{"a":1}`, `[2]`}},
+		{" \n", nil},
+		{"", nil},
+	}
+	for _, c := range cases {
+		values, err := DecodeAll(strings.NewReader(c.stream))
+		if err != nil {
+			t.Errorf("%q: %v", c.stream, err)
+			continue
+		}
+		if len(values) != len(c.want) {
+			t.Errorf("%q: got %d values", c.stream, len(values))
+			continue
+		}
+		for i, v := range values {
+			if b, _ := v.Marshal(); string(b) != c.want[i] {
+				t.Errorf("%q: got %s, want %s", c.stream, b, c.want[i])
+			}
+		}
+	}
+
+	values, err := DecodeAll(strings.NewReader("{\"a\":1}\n[1] garbage"))
+	var e *SyntaxError
+	if len(values) != 2 || !errors.As(err, &e) || e.Position.Line != 2 || e.Position.Column != 5 {
+		t.Error(values, err)
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	d := NewDecoder(strings.NewReader("{\"id\": 1}\n{\n  \"id\": 2\n}"))
+	var ids []int64
+	for d.Next() {
+		id, err := d.Value().Get("id").Int64()
+		if err != nil {
+			t.Error(err)
+		}
+		ids = append(ids, id)
+	}
+	if d.Err() != nil || len(ids) != 2 || ids[1] != 2 {
+		t.Error(ids, d.Err())
+	}
+	if d.Next() || d.Value() != nil {
+		t.Error("expected the end of the stream")
+	}
+
+	d = NewDecoder(strings.NewReader(`{"a": 1} {"b": `))
+	if !d.Next() || d.Next() || d.Err() == nil {
+		t.Error(d.Err())
+	}
+}
@@ -0,0 +1,350 @@
+package jason
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DiffKind tells how a value differs between two documents.
+type DiffKind int
+
+const (
+	DiffAdded DiffKind = iota
+	DiffRemoved
+	DiffChanged
+	DiffTypeChanged
+)
+
+func (k DiffKind) String() string {
+	switch k {
+	case DiffAdded:
+		return "added"
+	case DiffRemoved:
+		return "removed"
+	case DiffChanged:
+		return "changed"
+	case DiffTypeChanged:
+		return "type changed"
+	}
+	return "unknown"
+}
+
+// Difference is a single difference found by Compare.
+// Path is a JSON Pointer into the old document, or into the new one for added values.
+// Old is nil for added values and New is nil for removed ones.
+type Difference struct {
+	Path string
+	Kind DiffKind
+	Old  *Value
+	New  *Value
+}
+
+func (d Difference) String() string {
+	switch d.Kind {
+	case DiffAdded:
+		return fmt.Sprintf("%s: added %s", displayPath(d.Path), compactJSON(d.New))
+	case DiffRemoved:
+		return fmt.Sprintf("%s: removed %s", displayPath(d.Path), compactJSON(d.Old))
+	}
+	return fmt.Sprintf("%s: %s %s -> %s", displayPath(d.Path), d.Kind, compactJSON(d.Old), compactJSON(d.New))
+}
+
+// CompareOption configures Compare.
+type CompareOption func(*compareOptions)
+
+type compareOptions struct {
+	ignore     map[string]bool
+	ignoreKeys map[string]bool
+	unordered  bool
+	tolerance  float64
+}
+
+// Ignores the values at the given JSON Pointers, including everything below them.
+func IgnorePaths(paths ...string) CompareOption {
+	return func(o *compareOptions) {
+		for _, path := range paths {
+			o.ignore[path] = true
+		}
+	}
+}
+
+// Ignores object members with the given names at any depth.
+func IgnoreKeys(keys ...string) CompareOption {
+	return func(o *compareOptions) {
+		for _, key := range keys {
+			o.ignoreKeys[key] = true
+		}
+	}
+}
+
+// Treats arrays as unordered collections.
+// Elements are matched with an equal element anywhere in the other array.
+func UnorderedArrays() CompareOption {
+	return func(o *compareOptions) {
+		o.unordered = true
+	}
+}
+
+// Treats numbers as equal if they differ by no more than epsilon.
+func NumericTolerance(epsilon float64) CompareOption {
+	return func(o *compareOptions) {
+		o.tolerance = epsilon
+	}
+}
+
+// Compares two documents and returns their structural differences in document order,
+// with object members in key order. Numbers are compared by value, so 1 and 1.0 are equal.
+// Arrays are aligned on their longest common subsequence, so an inserted element is
+// reported as one addition rather than a change of every element after it.
+// Example:
+//
+//	diffs := jason.Compare(expected, actual, jason.IgnorePaths("/id"))
+//	if len(diffs) > 0 {
+//		t.Error(jason.FormatDifferences(diffs, false))
+//	}
+func Compare(a, b *Value, opts ...CompareOption) []Difference {
+	o := newCompareOptions(opts)
+	return o.compare(nil, a.raw(), b.raw(), nil)
+}
+
+// Reports whether two values are semantically equal: numbers are compared by value,
+// so 1, 1.0 and 1e0 are equal, and the order of object members doesn't matter.
+// Accepts the same options as Compare, e.g. NumericTolerance, UnorderedArrays and IgnoreKeys.
+// Values with an error are never equal.
+// Example:
+//
+//	if !actual.Equal(expected, jason.IgnoreKeys("created_at")) {
+//		...
+//	}
+func (v *Value) Equal(other *Value, opts ...CompareOption) bool {
+	if v == nil || other == nil || v.Err != nil || other.Err != nil {
+		return false
+	}
+	o := newCompareOptions(opts)
+	return o.equal(nil, v.raw(), other.raw())
+}
+
+func newCompareOptions(opts []CompareOption) *compareOptions {
+	o := &compareOptions{ignore: map[string]bool{}, ignoreKeys: map[string]bool{}}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func (o *compareOptions) compare(tokens []string, a, b interface{}, diffs []Difference) []Difference {
+	if len(o.ignore) > 0 && o.ignore[formatPointer(tokens)] {
+		return diffs
+	}
+
+	ka, kb := kindOf(a), kindOf(b)
+	if ka != kb {
+		return append(diffs, o.difference(tokens, DiffTypeChanged, a, b))
+	}
+
+	switch ka {
+	case KindObject:
+		return o.compareObjects(tokens, a.(map[string]interface{}), b.(map[string]interface{}), diffs)
+	case KindArray:
+		if o.unordered {
+			return o.compareUnordered(tokens, a.([]interface{}), b.([]interface{}), diffs)
+		}
+		return o.compareArrays(tokens, a.([]interface{}), b.([]interface{}), diffs)
+	case KindNumber:
+		if !o.numbersEqual(a.(json.Number), b.(json.Number)) {
+			return append(diffs, o.difference(tokens, DiffChanged, a, b))
+		}
+	default:
+		if !dataEqual(a, b) {
+			return append(diffs, o.difference(tokens, DiffChanged, a, b))
+		}
+	}
+	return diffs
+}
+
+func (o *compareOptions) difference(tokens []string, kind DiffKind, a, b interface{}) Difference {
+	d := Difference{Path: formatPointer(tokens), Kind: kind}
+	if kind != DiffAdded {
+		d.Old = &Value{data: a, exists: true}
+	}
+	if kind != DiffRemoved {
+		d.New = &Value{data: b, exists: true}
+	}
+	return d
+}
+
+func (o *compareOptions) equal(tokens []string, a, b interface{}) bool {
+	if len(o.ignore) == 0 && len(o.ignoreKeys) == 0 && !o.unordered && o.tolerance == 0 {
+		return dataEqual(a, b)
+	}
+	return len(o.compare(tokens, a, b, nil)) == 0
+}
+
+func (o *compareOptions) numbersEqual(a, b json.Number) bool {
+	if compareNumbers(a, b) == 0 {
+		return true
+	}
+	if o.tolerance == 0 {
+		return false
+	}
+	x, _ := strconv.ParseFloat(string(a), 64)
+	y, _ := strconv.ParseFloat(string(b), 64)
+	return math.Abs(x-y) <= o.tolerance
+}
+
+func (o *compareOptions) compareObjects(tokens []string, a, b map[string]interface{}, diffs []Difference) []Difference {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if o.ignoreKeys[key] {
+			continue
+		}
+		child := append(tokens[:len(tokens):len(tokens)], key)
+		if len(o.ignore) > 0 && o.ignore[formatPointer(child)] {
+			continue
+		}
+		av, inA := a[key]
+		bv, inB := b[key]
+		switch {
+		case !inB:
+			diffs = append(diffs, o.difference(child, DiffRemoved, av, nil))
+		case !inA:
+			diffs = append(diffs, o.difference(child, DiffAdded, nil, bv))
+		default:
+			diffs = o.compare(child, av, bv, diffs)
+		}
+	}
+	return diffs
+}
+
+func (o *compareOptions) compareArrays(tokens []string, a, b []interface{}, diffs []Difference) []Difference {
+	element := func(i int) []string {
+		return append(tokens[:len(tokens):len(tokens)], strconv.Itoa(i))
+	}
+
+	ai, bj := 0, 0
+	pairs := lcsPairs(len(a), len(b), func(i, j int) bool { return o.equal(element(i), a[i], b[j]) })
+	for _, pair := range append(pairs, [2]int{len(a), len(b)}) {
+		// Unmatched elements are compared pairwise, the rest was removed or added.
+		i, j := ai, bj
+		for ; i < pair[0] && j < pair[1]; i, j = i+1, j+1 {
+			diffs = o.compare(element(i), a[i], b[j], diffs)
+		}
+		for ; i < pair[0]; i++ {
+			diffs = append(diffs, o.difference(element(i), DiffRemoved, a[i], nil))
+		}
+		for ; j < pair[1]; j++ {
+			diffs = append(diffs, o.difference(element(j), DiffAdded, nil, b[j]))
+		}
+		ai, bj = pair[0]+1, pair[1]+1
+	}
+	return diffs
+}
+
+func (o *compareOptions) compareUnordered(tokens []string, a, b []interface{}, diffs []Difference) []Difference {
+	element := func(i int) []string {
+		return append(tokens[:len(tokens):len(tokens)], strconv.Itoa(i))
+	}
+
+	matched := make([]bool, len(b))
+	for i := range a {
+		found := false
+		for j := range b {
+			if !matched[j] && o.equal(element(i), a[i], b[j]) {
+				matched[j], found = true, true
+				break
+			}
+		}
+		if !found {
+			diffs = append(diffs, o.difference(element(i), DiffRemoved, a[i], nil))
+		}
+	}
+	for j := range b {
+		if !matched[j] {
+			diffs = append(diffs, o.difference(element(j), DiffAdded, nil, b[j]))
+		}
+	}
+	return diffs
+}
+
+const (
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+	colorCyan  = "\x1b[36m"
+	colorReset = "\x1b[0m"
+)
+
+// Returns the path of a difference for reports, where the root is named (root).
+// "/" would be the member with the empty name.
+func displayPath(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
+
+// Renders differences as a unified text report.
+// Each difference starts with an @@ header naming the path and kind, followed by
+// the old value on - lines and the new value on + lines. The root is named (root).
+// ANSI colors are used when color is true.
+// Example output:
+//
+//	@@ /friends/1/name (changed) @@
+//	-"bob"
+//	+"robert"
+func FormatDifferences(diffs []Difference, color bool) string {
+	paint := func(c, s string) string {
+		if !color {
+			return s
+		}
+		return c + s + colorReset
+	}
+
+	var b strings.Builder
+	for _, d := range diffs {
+		b.WriteString(paint(colorCyan, fmt.Sprintf("@@ %s (%s) @@", displayPath(d.Path), d.Kind)))
+		b.WriteByte('\n')
+		if d.Old != nil {
+			for _, line := range strings.Split(indentedJSON(d.Old), "\n") {
+				b.WriteString(paint(colorRed, "-"+line))
+				b.WriteByte('\n')
+			}
+		}
+		if d.New != nil {
+			for _, line := range strings.Split(indentedJSON(d.New), "\n") {
+				b.WriteString(paint(colorGreen, "+"+line))
+				b.WriteByte('\n')
+			}
+		}
+	}
+	return b.String()
+}
+
+func compactJSON(v *Value) string {
+	b, err := json.Marshal(v.raw())
+	if err != nil {
+		return err.Error()
+	}
+	return string(b)
+}
+
+func indentedJSON(v *Value) string {
+	b, err := json.MarshalIndent(v.raw(), "", "  ")
+	if err != nil {
+		return err.Error()
+	}
+	return string(b)
+}
@@ -0,0 +1,165 @@
+package jason
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	a, _ := NewValueFromBytes([]byte(`{
+		"id": 1,
+		"name": "anton",
+		"age": 29,
+		"score": 1.0,
+		"tags": ["a", "b", "c"],
+		"address": {"street": "Street 42", "city": "Stockholm"},
+		"removed": true
+	}`))
+	b, _ := NewValueFromBytes([]byte(`{
+		"id": 2,
+		"name": "anton",
+		"age": "29",
+		"score": 1,
+		"tags": ["x", "a", "b", "d"],
+		"address": {"street": "Street 43", "city": "Stockholm"},
+		"added": null
+	}`))
+
+	diffs := Compare(a, b)
+	want := []string{
+		`/added: added null`,
+		`/address/street: changed "Street 42" -> "Street 43"`,
+		`/age: type changed 29 -> "29"`,
+		`/id: changed 1 -> 2`,
+		`/removed: removed true`,
+		`/tags/0: added "x"`,
+		`/tags/2: changed "c" -> "d"`,
+	}
+	if len(diffs) != len(want) {
+		t.Fatal(diffs)
+	}
+	for i := range want {
+		if diffs[i].String() != want[i] {
+			t.Errorf("got %s, want %s", diffs[i], want[i])
+		}
+	}
+	if diffs[0].Old != nil || diffs[0].Kind != DiffAdded {
+		t.Error(diffs[0])
+	}
+	if diffs[4].New != nil || diffs[4].Kind != DiffRemoved {
+		t.Error(diffs[4])
+	}
+
+	if diffs := Compare(a, a); len(diffs) != 0 {
+		t.Error(diffs)
+	}
+}
+
+func TestCompareOptions(t *testing.T) {
+	a, _ := NewValueFromBytes([]byte(`{"id": 1, "meta": {"at": "now"}, "list": [1, 2, {"x": 3}], "pi": 3.14159}`))
+	b, _ := NewValueFromBytes([]byte(`{"id": 2, "meta": {"at": "later"}, "list": [{"x": 3}, 2, 1], "pi": 3.1416}`))
+
+	if diffs := Compare(a, b); len(diffs) == 0 {
+		t.Error("expected differences")
+	}
+
+	diffs := Compare(a, b, IgnorePaths("/id", "/meta"), UnorderedArrays(), NumericTolerance(0.001))
+	if len(diffs) != 0 {
+		t.Error(diffs)
+	}
+
+	diffs = Compare(a, b, IgnorePaths("/id", "/meta"), UnorderedArrays())
+	if len(diffs) != 1 || diffs[0].Path != "/pi" {
+		t.Error(diffs)
+	}
+
+	c, _ := NewValueFromBytes([]byte(`[1, 1, 2]`))
+	d, _ := NewValueFromBytes([]byte(`[2, 1, 3]`))
+	diffs = Compare(c, d, UnorderedArrays())
+	if len(diffs) != 2 || diffs[0].String() != `/1: removed 1` || diffs[1].String() != `/2: added 3` {
+		t.Error(diffs)
+	}
+
+	one, _ := NewValueFromBytes([]byte(`1`))
+	two, _ := NewValueFromBytes([]byte(`2`))
+	if diffs := Compare(one, two); len(diffs) != 1 || diffs[0].String() != `(root): changed 1 -> 2` {
+		t.Error(diffs)
+	}
+}
+
+func TestFormatDifferences(t *testing.T) {
+	a, _ := NewValueFromBytes([]byte(`{"name": "bob", "list": [1]}`))
+	b, _ := NewValueFromBytes([]byte(`{"name": "robert", "list": [1, {"a": 1}]}`))
+
+	report := FormatDifferences(Compare(a, b), false)
+	want := strings.Join([]string{
+		`@@ /list/1 (added) @@`,
+		`+{`,
+		`+  "a": 1`,
+		`+}`,
+		`@@ /name (changed) @@`,
+		`-"bob"`,
+		`+"robert"`,
+		``,
+	}, "\n")
+	if report != want {
+		t.Errorf("got\n%s\nwant\n%s", report, want)
+	}
+
+	root, _ := NewValueFromBytes([]byte(`1`))
+	empty, _ := NewValueFromBytes([]byte(`{"": 1}`))
+	if got := FormatDifferences(Compare(root, empty), false); !strings.HasPrefix(got, "@@ (root) (type changed) @@\n") {
+		t.Errorf("root difference: %s", got)
+	}
+	if got := FormatDifferences(Compare(empty, b), false); !strings.Contains(got, "@@ / (removed) @@\n") {
+		t.Errorf("difference of the empty member name: %s", got)
+	}
+
+	colored := FormatDifferences(Compare(a, b), true)
+	if !strings.Contains(colored, colorRed+`-"bob"`+colorReset) || !strings.Contains(colored, colorGreen+`+"robert"`+colorReset) {
+		t.Error(colored)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	cases := []struct {
+		a, b  string
+		opts  []CompareOption
+		equal bool
+	}{
+		{`1`, `1.0`, nil, true},
+		{`1`, `1e0`, nil, true},
+		{`100`, `1E2`, nil, true},
+		{`-0`, `0`, nil, true},
+		{`12345678901234567890`, `12345678901234567891`, nil, false},
+		{`12345678901234567890`, `1.234567890123456789e19`, nil, true},
+		{`{"a": 1, "b": [true, null]}`, `{"b": [true, null], "a": 1.00}`, nil, true},
+		{`{"a": 1}`, `{"a": 1, "b": 2}`, nil, false},
+		{`[1, 2]`, `[2, 1]`, nil, false},
+		{`[1, 2]`, `[2, 1]`, []CompareOption{UnorderedArrays()}, true},
+		{`[1, 2]`, `[2, 1, 1]`, []CompareOption{UnorderedArrays()}, false},
+		{`0.1`, `0.1000001`, nil, false},
+		{`0.1`, `0.1000001`, []CompareOption{NumericTolerance(1e-6)}, true},
+		{`{"a": {"ts": 1}, "ts": 2}`, `{"a": {"ts": 3}}`, []CompareOption{IgnoreKeys("ts")}, true},
+		{`"1"`, `1`, nil, false},
+		{`null`, `{}`, nil, false},
+	}
+	for _, c := range cases {
+		a, err := NewValueFromBytes([]byte(c.a))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := NewValueFromBytes([]byte(c.b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Equal(b, c.opts...) != c.equal {
+			t.Errorf("%s == %s: expected %v", c.a, c.b, c.equal)
+		}
+	}
+
+	v, _ := NewValueFromBytes([]byte(`1`))
+	if v.Equal(nil) || v.Equal(&Value{Err: ErrNotNumber}) {
+		t.Error("values with errors should not be equal")
+	}
+}
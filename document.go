@@ -0,0 +1,417 @@
+package jason
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Document is a JSON, JSONC or JSON5 text that can be edited in place.
+// Edits only touch the text of the changed values, so comments, whitespace and the order of
+// members are kept everywhere else.
+type Document struct {
+	src     []byte
+	value   *Value
+	indent  string // One level of indentation, empty if the document is on a single line
+	newline string
+}
+
+var documentOptions = ParseOptions{Relaxed: true, Strict: true}
+
+// Parses a document for editing. Comments and the other extensions of ParseOptions.Relaxed are accepted.
+// Example:
+//
+//	doc, err := jason.ParseDocument(b)
+//	err = doc.Set("/version", "1.2.4")
+//	err = ioutil.WriteFile("config.json", doc.Bytes(), 0644)
+func ParseDocument(b []byte) (*Document, error) {
+	d := &Document{}
+	if err := d.parse(append([]byte(nil), b...)); err != nil {
+		return nil, err
+	}
+	d.indent = d.detectIndent()
+	d.newline = "\n"
+	if bytes.Contains(d.src, []byte("\r\n")) {
+		d.newline = "\r\n"
+	}
+	return d, nil
+}
+
+func (d *Document) parse(src []byte) error {
+	v, err := documentOptions.NewValueFromBytes(src)
+	if err != nil {
+		return err
+	}
+	d.src, d.value = src, v
+	return nil
+}
+
+// Returns the value of the document as of the last edit.
+func (d *Document) Value() *Value {
+	return d.value
+}
+
+// Returns the text of the document with all edits.
+func (d *Document) Bytes() []byte {
+	return d.src
+}
+
+// Sets the value at the JSON Pointer path, see Value.Set.
+// The text of an existing value is replaced. New members and elements go after the last one,
+// indented like it, and missing intermediate objects and arrays are created.
+// Example:
+//
+//	err := doc.Set("/dependencies/jason", "^1.3.0")
+func (d *Document) Set(path string, value interface{}) error {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return &PointerError{Pointer: path, Err: err}
+	}
+	data, err := toData(value)
+	if err != nil {
+		return err
+	}
+	t, found, err := d.resolve(path, tokens)
+	if err != nil {
+		return err
+	}
+
+	if found == len(tokens) {
+		text, err := d.format(data, lineIndent(d.src, t.node.pos.Offset))
+		if err != nil {
+			return err
+		}
+		return d.splice(edit{t.node.pos.Offset, t.node.end, text})
+	}
+
+	rest := tokens[found:]
+	if len(rest) > 1 {
+		nested := &Value{}
+		if err := nested.Set(formatPointer(rest[1:]), data); err != nil {
+			return err
+		}
+		data = nested.raw()
+	}
+	if s, ok := t.data.([]interface{}); ok && rest[0] != "-" && rest[0] != strconv.Itoa(len(s)) {
+		return &PointerError{Pointer: path, Path: formatPointer(tokens[:found+1]), Err: ErrIndexOutOfRange, Position: t.node.pos}
+	}
+	return d.add(t.node, rest[0], data)
+}
+
+// Deletes the member or element at the JSON Pointer path, see Value.Delete.
+// The comma after it and the comments before it and on the rest of its line go with it.
+// Example:
+//
+//	err := doc.Delete("/deprecated")
+func (d *Document) Delete(path string) error {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return &PointerError{Pointer: path, Err: err}
+	}
+	if len(tokens) == 0 {
+		return &PointerError{Pointer: path, Err: ErrInvalidPointer}
+	}
+	t, found, err := d.resolve(path, tokens)
+	if err != nil {
+		return err
+	}
+	if found < len(tokens) {
+		var missing error = KeyNotFoundError{tokens[found]}
+		if _, ok := t.data.([]interface{}); ok {
+			missing = ErrIndexOutOfRange
+		}
+		return &PointerError{Pointer: path, Path: formatPointer(tokens[:found+1]), Err: missing, Position: t.node.pos}
+	}
+
+	for {
+		isObject := t.parent.keys != nil
+		if err := d.splice(d.removal(t)...); err != nil {
+			return err
+		}
+		// An earlier member with the same name shows up again
+		if t, found, _ = d.resolve(path, tokens); !isObject || found < len(tokens) {
+			return nil
+		}
+	}
+}
+
+// A value in the document and the object or array it is in.
+type docTarget struct {
+	node   *parseNode
+	data   interface{}
+	parent *parseNode // nil for the root
+	index  int        // Of node in parent.children
+}
+
+// Follows the tokens as far as the document has values for them.
+// Returns the deepest value found and the number of tokens that lead to it.
+func (d *Document) resolve(ptr string, tokens []string) (docTarget, int, error) {
+	t := docTarget{node: d.value.node, data: d.value.raw()}
+	for i, token := range tokens {
+		data, err := pointerStep(t.data, token)
+		if _, missing := err.(KeyNotFoundError); missing || err == ErrIndexOutOfRange {
+			return t, i, nil
+		}
+		if err != nil {
+			return t, i, &PointerError{Pointer: ptr, Path: formatPointer(tokens[:i+1]), Err: err, Position: t.node.pos}
+		}
+
+		var index int
+		if t.node.keys != nil {
+			index = t.node.member(token)
+		} else {
+			index, _ = strconv.Atoi(token)
+		}
+		t = docTarget{node: t.node.children[index], data: data, parent: t.node, index: index}
+	}
+	return t, len(tokens), nil
+}
+
+// Adds a member or element after the last one of the object or array of the node.
+func (d *Document) add(n *parseNode, key string, data interface{}) error {
+	var name string
+	if n.keys != nil {
+		quoted, err := d.format(key, "")
+		if err != nil {
+			return err
+		}
+		name = quoted + d.colon(n)
+	}
+
+	open := n.pos.Offset + 1
+	if len(n.children) == 0 {
+		var sep, indent string
+		if bytes.IndexByte(d.src[open:n.end-1], '\n') >= 0 {
+			indent = lineIndent(d.src, n.pos.Offset) + d.indent
+			sep = d.newline + indent
+		}
+		text, err := d.format(data, indent)
+		if err != nil {
+			return err
+		}
+		at := lineEnd(d.src, open)
+		return d.splice(edit{at, at, sep + name + text})
+	}
+
+	k := len(n.children) - 1
+	last, start := n.children[k], n.start(k)
+	var sep, indent string
+	switch {
+	case onOwnLine(d.src, start):
+		indent = lineIndent(d.src, start)
+		sep = d.newline + indent
+	case k > 0:
+		comma, _ := d.comma(n.children[k-1].end)
+		if gap := d.src[comma+1 : start]; len(bytes.Trim(gap, " \t")) == 0 {
+			sep = string(gap)
+		}
+	case start > open:
+		sep = " "
+	}
+	text, err := d.format(data, indent)
+	if err != nil {
+		return err
+	}
+
+	if comma, ok := d.comma(last.end); ok {
+		// Keep the trailing comma last
+		at := lineEnd(d.src, comma+1)
+		return d.splice(edit{at, at, sep + name + text + ","})
+	}
+	at := lineEnd(d.src, last.end)
+	return d.splice(edit{last.end, last.end, ","}, edit{at, at, sep + name + text})
+}
+
+// Returns the edits that remove the member or element of the target.
+func (d *Document) removal(t docTarget) []edit {
+	n, k := t.parent, t.index
+	from := lineEnd(d.src, n.pos.Offset+1)
+	if k > 0 {
+		comma, _ := d.comma(n.children[k-1].end)
+		from = lineEnd(d.src, comma+1)
+	}
+	if comma, ok := d.comma(t.node.end); ok {
+		to := lineEnd(d.src, comma+1)
+		sameLine := to < len(d.src) && d.src[to] != '\n' && d.src[to] != '\r'
+		if k == 0 && (to == comma+1 || sameLine) && !onOwnLine(d.src, n.start(0)) {
+			// On a single line the next one moves to where this one started
+			from = n.start(0)
+			for to < len(d.src) && (d.src[to] == ' ' || d.src[to] == '\t') {
+				to++
+			}
+		}
+		return []edit{{from, to, ""}}
+	}
+
+	to := lineEnd(d.src, t.node.end)
+	if k == 0 {
+		return []edit{{from, to, ""}}
+	}
+	// The last one has no comma, so the one before loses its comma
+	comma, _ := d.comma(n.children[k-1].end)
+	return []edit{{comma, comma + 1, ""}, {from, to, ""}}
+}
+
+// A replacement of the text from from to to.
+type edit struct {
+	from, to int
+	text     string
+}
+
+// Applies the edits, which must be in document order, and parses the result.
+func (d *Document) splice(edits ...edit) error {
+	src := d.src
+	for i := len(edits) - 1; i >= 0; i-- {
+		e := edits[i]
+		next := make([]byte, 0, len(src)-(e.to-e.from)+len(e.text))
+		next = append(next, src[:e.from]...)
+		next = append(next, e.text...)
+		src = append(next, src[e.to:]...)
+	}
+	return d.parse(src)
+}
+
+// Formats data like the document, indented by prefix after the first line.
+func (d *Document) format(data interface{}, prefix string) (string, error) {
+	var buf bytes.Buffer
+	e := json.NewEncoder(&buf)
+	e.SetEscapeHTML(false)
+	if d.indent != "" {
+		e.SetIndent(prefix, d.indent)
+	}
+	if err := e.Encode(data); err != nil {
+		return "", err
+	}
+	text := strings.TrimSuffix(buf.String(), "\n")
+	return strings.Replace(text, "\n", d.newline, -1), nil
+}
+
+// Returns the indentation of the members of the root, if they are on lines of their own.
+func (d *Document) detectIndent() string {
+	root := d.value.node
+	if len(root.children) == 0 || !onOwnLine(d.src, root.start(0)) {
+		return ""
+	}
+	outer := lineIndent(d.src, root.pos.Offset)
+	return strings.TrimPrefix(lineIndent(d.src, root.start(0)), outer)
+}
+
+// Returns what separates the member names from the values in the object of the node.
+func (d *Document) colon(n *parseNode) string {
+	if len(n.children) == 0 {
+		return ": "
+	}
+	last := n.children[len(n.children)-1]
+	gap := d.src[keyEnd(d.src, last.keyPos.Offset):last.pos.Offset]
+	if string(bytes.Trim(gap, " \t")) != ":" {
+		return ": "
+	}
+	return string(gap)
+}
+
+// Returns the index of the comma after a member or element, if there is one.
+func (d *Document) comma(end int) (int, bool) {
+	i := skipTrivia(d.src, end)
+	return i, i < len(d.src) && d.src[i] == ','
+}
+
+// Returns the offset of a member or element, which is where the member name starts for objects.
+func (n *parseNode) start(i int) int {
+	if n.keys != nil {
+		return n.children[i].keyPos.Offset
+	}
+	return n.children[i].pos.Offset
+}
+
+// Returns the offset after the member name starting at i.
+func keyEnd(src []byte, i int) int {
+	if quote := src[i]; quote == '"' || quote == '\'' {
+		for i++; src[i] != quote; i++ {
+			if src[i] == '\\' {
+				i++
+			}
+		}
+		return i + 1
+	}
+	for i < len(src) && isIdentifierPart(src[i]) {
+		i++
+	}
+	return i
+}
+
+// Skips whitespace and comments.
+func skipTrivia(src []byte, i int) int {
+	for i < len(src) {
+		switch {
+		case strings.IndexByte(" \t\n\r\v\f", src[i]) >= 0:
+			i++
+		case bytes.HasPrefix(src[i:], []byte("//")):
+			if end := bytes.IndexByte(src[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(src)
+			}
+		case bytes.HasPrefix(src[i:], []byte("/*")):
+			i += 4 + bytes.Index(src[i+2:], []byte("*/"))
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// Returns the end of the line if only blanks and comments follow i on it. Otherwise returns the end of
+// the block comments that follow i on its line, or i if there are none. The comments after a member or
+// element on its line go with it when it is removed.
+func lineEnd(src []byte, i int) int {
+	end, j := i, i
+	for {
+		for j < len(src) && (src[j] == ' ' || src[j] == '\t') {
+			j++
+		}
+		if !bytes.HasPrefix(src[j:], []byte("/*")) {
+			break
+		}
+		close := bytes.Index(src[j+2:], []byte("*/"))
+		if close < 0 || bytes.IndexByte(src[j:j+2+close], '\n') >= 0 {
+			// A comment over several lines stays
+			return end
+		}
+		j += close + 4
+		end = j
+	}
+	switch {
+	case bytes.HasPrefix(src[j:], []byte("//")):
+		if k := bytes.IndexByte(src[j:], '\n'); k >= 0 {
+			j += k
+		} else {
+			j = len(src)
+		}
+	case end == i || (j < len(src) && src[j] != '\n' && src[j] != '\r'):
+		return end
+	}
+	if j > 0 && j < len(src) && src[j] == '\n' && src[j-1] == '\r' {
+		j--
+	}
+	return j
+}
+
+// Reports whether only blanks come before i on its line.
+func onOwnLine(src []byte, i int) bool {
+	j := i - 1
+	for j >= 0 && (src[j] == ' ' || src[j] == '\t') {
+		j--
+	}
+	return j < 0 || src[j] == '\n'
+}
+
+// Returns the blanks at the start of the line of i.
+func lineIndent(src []byte, i int) string {
+	start := bytes.LastIndexByte(src[:i], '\n') + 1
+	end := start
+	for end < i && (src[end] == ' ' || src[end] == '\t') {
+		end++
+	}
+	return string(src[start:end])
+}
@@ -0,0 +1,278 @@
+package jason
+
+import (
+	"errors"
+	"testing"
+)
+
+const config = `// Service configuration
+{
+  "name": "api", // shown in logs
+  "version": "1.2.3",
+
+  /* Ports */
+  "ports": [80, 443],
+  "tls": {
+    "cert": "a.pem",
+    "key": "a.key"
+  },
+  "tags": [],
+  "extra": {
+  }
+}
+`
+
+func TestDocumentEdits(t *testing.T) {
+	cases := []struct {
+		name string
+		edit func(d *Document) error
+		want string
+	}{
+		{"replace", func(d *Document) error { return d.Set("/version", "1.2.4") }, `// Service configuration
+{
+  "name": "api", // shown in logs
+  "version": "1.2.4",
+
+  /* Ports */
+  "ports": [80, 443],
+  "tls": {
+    "cert": "a.pem",
+    "key": "a.key"
+  },
+  "tags": [],
+  "extra": {
+  }
+}
+`},
+		{"add member", func(d *Document) error { return d.Set("/tls/ca", "<ca>.pem") }, `// Service configuration
+{
+  "name": "api", // shown in logs
+  "version": "1.2.3",
+
+  /* Ports */
+  "ports": [80, 443],
+  "tls": {
+    "cert": "a.pem",
+    "key": "a.key",
+    "ca": "<ca>.pem"
+  },
+  "tags": [],
+  "extra": {
+  }
+}
+`},
+		{"add element", func(d *Document) error { return d.Set("/ports/-", 8080) }, `// Service configuration
+{
+  "name": "api", // shown in logs
+  "version": "1.2.3",
+
+  /* Ports */
+  "ports": [80, 443, 8080],
+  "tls": {
+    "cert": "a.pem",
+    "key": "a.key"
+  },
+  "tags": [],
+  "extra": {
+  }
+}
+`},
+		{"add to empty", func(d *Document) error {
+			if err := d.Set("/tags/0", "web"); err != nil {
+				return err
+			}
+			return d.Set("/extra/limits", map[string]interface{}{"cpu": 2, "memory": []string{"1G"}})
+		}, `// Service configuration
+{
+  "name": "api", // shown in logs
+  "version": "1.2.3",
+
+  /* Ports */
+  "ports": [80, 443],
+  "tls": {
+    "cert": "a.pem",
+    "key": "a.key"
+  },
+  "tags": ["web"],
+  "extra": {
+    "limits": {
+      "cpu": 2,
+      "memory": [
+        "1G"
+      ]
+    }
+  }
+}
+`},
+		{"add intermediates", func(d *Document) error { return d.Set("/log/level", "debug") }, `// Service configuration
+{
+  "name": "api", // shown in logs
+  "version": "1.2.3",
+
+  /* Ports */
+  "ports": [80, 443],
+  "tls": {
+    "cert": "a.pem",
+    "key": "a.key"
+  },
+  "tags": [],
+  "extra": {
+  },
+  "log": {
+    "level": "debug"
+  }
+}
+`},
+		{"delete first", func(d *Document) error { return d.Delete("/name") }, `// Service configuration
+{
+  "version": "1.2.3",
+
+  /* Ports */
+  "ports": [80, 443],
+  "tls": {
+    "cert": "a.pem",
+    "key": "a.key"
+  },
+  "tags": [],
+  "extra": {
+  }
+}
+`},
+		{"delete with comment", func(d *Document) error { return d.Delete("/ports") }, `// Service configuration
+{
+  "name": "api", // shown in logs
+  "version": "1.2.3",
+  "tls": {
+    "cert": "a.pem",
+    "key": "a.key"
+  },
+  "tags": [],
+  "extra": {
+  }
+}
+`},
+		{"delete last", func(d *Document) error { return d.Delete("/tls/key") }, `// Service configuration
+{
+  "name": "api", // shown in logs
+  "version": "1.2.3",
+
+  /* Ports */
+  "ports": [80, 443],
+  "tls": {
+    "cert": "a.pem"
+  },
+  "tags": [],
+  "extra": {
+  }
+}
+`},
+		{"delete element", func(d *Document) error { return d.Delete("/ports/1") }, `// Service configuration
+{
+  "name": "api", // shown in logs
+  "version": "1.2.3",
+
+  /* Ports */
+  "ports": [80],
+  "tls": {
+    "cert": "a.pem",
+    "key": "a.key"
+  },
+  "tags": [],
+  "extra": {
+  }
+}
+`},
+	}
+	for _, c := range cases {
+		d, err := ParseDocument([]byte(config))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.edit(d); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if string(d.Bytes()) != c.want {
+			t.Errorf("%s: got\n%s", c.name, d.Bytes())
+		}
+	}
+}
+
+func TestDocumentLayouts(t *testing.T) {
+	cases := []struct {
+		doc  string
+		edit func(d *Document) error
+		want string
+	}{
+		{`{"a":1}`, func(d *Document) error { return d.Set("/b", []int{1, 2}) }, `{"a":1,"b":[1,2]}`},
+		{`{ "a": 1, "b": 2 }`, func(d *Document) error { return d.Set("/c", 3) }, `{ "a": 1, "b": 2, "c": 3 }`},
+		{`{ "a": 1 }`, func(d *Document) error { return d.Delete("/a") }, `{ }`},
+		{`[1, 2, 3]`, func(d *Document) error { return d.Delete("/0") }, `[2, 3]`},
+		{`{ "a": 1, "b": 2 }`, func(d *Document) error { return d.Delete("/a") }, `{ "b": 2 }`},
+		{`[1, 2, 3]`, func(d *Document) error { return d.Delete("/2") }, `[1, 2]`},
+		{"{\n  a: 1, // one\n  b: 2, // two\n}", func(d *Document) error { return d.Set("/c", 3) }, "{\n  a: 1, // one\n  b: 2, // two\n  \"c\": 3,\n}"},
+		{"{\n  a: 1, // one\n  b: 2 // two\n}", func(d *Document) error { return d.Set("/c", 3) }, "{\n  a: 1, // one\n  b: 2, // two\n  \"c\": 3\n}"},
+		{"{\n  a: 1, // one\n  b: 2 // two\n}", func(d *Document) error { return d.Delete("/b") }, "{\n  a: 1 // one\n}"},
+		{"{\r\n\t\"a\": 1\r\n}\r\n", func(d *Document) error { return d.Set("/b", map[string]int{"c": 1}) }, "{\r\n\t\"a\": 1,\r\n\t\"b\": {\r\n\t\t\"c\": 1\r\n\t}\r\n}\r\n"},
+		{`{"a": 1, "b": 2, "a": 3}`, func(d *Document) error { return d.Delete("/a") }, `{"b": 2}`},
+		{`{"a":1 /* c */, "b":2}`, func(d *Document) error { return d.Delete("/a") }, `{"b":2}`},
+		{`{"a": 1, /* c */ "b": 2}`, func(d *Document) error { return d.Delete("/a") }, `{"b": 2}`},
+		{`[0, 1 /* y */ ]`, func(d *Document) error { return d.Delete("/1") }, `[0 ]`},
+		{"{\n  \"a\": 1, /* c */\n  \"b\": 2\n}", func(d *Document) error { return d.Delete("/a") }, "{\n  \"b\": 2\n}"},
+		{"[\n  0,\n  1 /* y */ // z\n]", func(d *Document) error { return d.Delete("/1") }, "[\n  0\n]"},
+		{"[\n  0,\n  1 /* y\n  */\n]", func(d *Document) error { return d.Delete("/1") }, "[\n  0 /* y\n  */\n]"},
+		{`{"a": 1}`, func(d *Document) error { return d.Set("", "replaced") }, `"replaced"`},
+	}
+	for _, c := range cases {
+		d, err := ParseDocument([]byte(c.doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.edit(d); err != nil {
+			t.Errorf("%q: %v", c.doc, err)
+			continue
+		}
+		if string(d.Bytes()) != c.want {
+			t.Errorf("%q: got %q", c.doc, d.Bytes())
+		}
+	}
+}
+
+func TestDocumentValue(t *testing.T) {
+	d, _ := ParseDocument([]byte(config))
+	d.Set("/version", "2.0.0")
+	if version, _ := d.Value().Get("version").String(); version != "2.0.0" {
+		t.Error(version)
+	}
+	if pos := d.Value().Get("tls").Get("key").Position(); pos.Line != 10 {
+		t.Error(pos)
+	}
+}
+
+func TestDocumentErrors(t *testing.T) {
+	d, _ := ParseDocument([]byte(config))
+	cases := []struct {
+		err  error
+		want error
+	}{
+		{d.Set("/ports/5", 1), ErrIndexOutOfRange},
+		{d.Set("/ports/x", 1), ErrInvalidIndex},
+		{d.Set("/name/x", 1), ErrNotObject},
+		{d.Delete("/missing"), KeyNotFoundError{"missing"}},
+		{d.Delete("/ports/2"), ErrIndexOutOfRange},
+		{d.Delete(""), ErrInvalidPointer},
+		{d.Set("x", 1), ErrInvalidPointer},
+	}
+	for i, c := range cases {
+		var e *PointerError
+		if !errors.As(c.err, &e) || !errors.Is(c.err, c.want) || e.Position.IsValid() != (c.want != ErrInvalidPointer) {
+			t.Errorf("%d: got %v", i, c.err)
+		}
+	}
+	if string(d.Bytes()) != config {
+		t.Error("failed edits changed the document")
+	}
+	if _, err := ParseDocument([]byte(`{"a": 1} x`)); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,92 @@
+package jason
+
+import (
+	"fmt"
+)
+
+// DuplicatePolicy decides what happens when an object repeats a member name.
+type DuplicatePolicy int
+
+const (
+	// Keeps the last value, like encoding/json. This is the default.
+	DuplicateLastWins DuplicatePolicy = iota
+	// Keeps the first value and ignores the later ones.
+	DuplicateFirstWins
+	// Fails with a DuplicateKeyError.
+	DuplicateError
+	// Keeps the last value and makes every value available through Object.Values.
+	DuplicateCollect
+)
+
+// DuplicateKeyError is returned for a repeated member name when parsing with DuplicateError.
+type DuplicateKeyError struct {
+	Key      string
+	Path     string   // JSON Pointer of the member
+	Position Position // Position of the repeated member name
+	First    Position // Position of the first member name
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("%s: path %q: duplicate key %q, first seen at %s", e.Position, e.Path, e.Key, e.First)
+}
+
+// Error for a member name that was already read in the object of the node.
+func (n *parseNode) duplicateKeyError(key string, pos Position) error {
+	e := &DuplicateKeyError{Key: key, Path: "/" + escapePointerToken(key), Position: pos}
+	for i, k := range n.keys {
+		if k == key {
+			e.First = n.children[i].keyPos
+			break
+		}
+	}
+	return e
+}
+
+// Records another value of a repeated member name.
+func (n *parseNode) collect(key string, previous, value interface{}) {
+	if n.duplicates == nil {
+		n.duplicates = make(map[string][]interface{})
+	}
+	if n.duplicates[key] == nil {
+		n.duplicates[key] = []interface{}{previous}
+	}
+	n.duplicates[key] = append(n.duplicates[key], value)
+}
+
+// Returns every value of a member in document order.
+// Only objects parsed with DuplicateCollect keep the values of repeated names; otherwise the
+// member has one value. Returns nil if the member doesn't exist.
+// Example:
+//
+//	o, err := jason.ParseOptions{Duplicates: jason.DuplicateCollect}.NewObjectFromBytes(b)
+//	for _, role := range o.Values("role") {
+//		...
+//	}
+func (v *Object) Values(key string) []*Value {
+	node := v.sourceNode()
+	if values, ok := node.duplicateValues(key); ok {
+		children := make([]*Value, 0, len(values))
+		for i, name := range node.keys {
+			if name == key {
+				child := v.Value.child(key, values[len(children)], true)
+				child.node = node.children[i]
+				children = append(children, child)
+			}
+		}
+		return children
+	}
+
+	child, err := v.get(key)
+	if err != nil {
+		return nil
+	}
+	return []*Value{child}
+}
+
+func (n *parseNode) duplicateValues(key string) ([]interface{}, bool) {
+	if n == nil {
+		return nil, false
+	}
+	values, ok := n.duplicates[key]
+	return values, ok
+}
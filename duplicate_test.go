@@ -0,0 +1,98 @@
+package jason
+
+import (
+	"errors"
+	"testing"
+)
+
+const duplicateDoc = `{"role": "user", "name": "anton", "role": "admin"}`
+
+func TestDuplicatePolicies(t *testing.T) {
+	cases := []struct {
+		policy DuplicatePolicy
+		role   string
+		keys   int
+	}{
+		{DuplicateLastWins, "admin", 3},
+		{DuplicateFirstWins, "user", 2},
+		{DuplicateCollect, "admin", 3},
+	}
+	for _, c := range cases {
+		o, err := ParseOptions{Duplicates: c.policy}.NewObjectFromBytes([]byte(duplicateDoc))
+		if err != nil {
+			t.Errorf("%d: %v", c.policy, err)
+			continue
+		}
+		if role, _ := o.GetString("role"); role != c.role {
+			t.Errorf("%d: got %s", c.policy, role)
+		}
+		if pos := o.Get("role").KeyPosition(); c.role == "user" && pos.Column != 2 || c.role == "admin" && pos.Column != 35 {
+			t.Errorf("%d: got %v", c.policy, pos)
+		}
+		if node := o.sourceNode(); len(node.keys) != c.keys {
+			t.Errorf("%d: got %v", c.policy, node.keys)
+		}
+	}
+}
+
+func TestDuplicateError(t *testing.T) {
+	cases := []struct {
+		doc  string
+		path string
+		line int
+		col  int
+	}{
+		{duplicateDoc, "/role", 1, 35},
+		{`{"a": [1, {"b": {"x": 1,` + "\n" + `"x": 2}}]}`, "/a/1/b/x", 2, 1},
+		{`[{"a/b": 1, "a/b": 2}]`, "/0/a~1b", 1, 13},
+	}
+	for _, c := range cases {
+		_, err := ParseOptions{Duplicates: DuplicateError}.NewValueFromBytes([]byte(c.doc))
+		var e *DuplicateKeyError
+		if !errors.As(err, &e) {
+			t.Errorf("%s: got %v", c.doc, err)
+			continue
+		}
+		if e.Path != c.path || e.Position.Line != c.line || e.Position.Column != c.col || !e.First.IsValid() {
+			t.Errorf("%s: got %s at %v", c.doc, e.Path, e.Position)
+		}
+	}
+
+	_, err := ParseOptions{Duplicates: DuplicateError}.NewValueFromBytes([]byte(duplicateDoc))
+	if err.Error() != `line 1, column 35: path "/role": duplicate key "role", first seen at line 1, column 2` {
+		t.Error(err)
+	}
+	if _, err := (ParseOptions{Duplicates: DuplicateError}).NewValueFromBytes([]byte(`{"a": {"a": 1}, "b": [{"a": 1}, {"a": 2}]}`)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestValues(t *testing.T) {
+	o, _ := ParseOptions{Duplicates: DuplicateCollect}.NewObjectFromBytes([]byte(duplicateDoc))
+	values := o.Values("role")
+	if len(values) != 2 {
+		t.Fatal(values)
+	}
+	if s, _ := values[0].String(); s != "user" || values[0].Position().Column != 10 {
+		t.Error(s, values[0].Position())
+	}
+	if s, _ := values[1].String(); s != "admin" || values[1].Position().Column != 43 {
+		t.Error(s, values[1].Position())
+	}
+	if values := o.Values("name"); len(values) != 1 {
+		t.Error(values)
+	}
+	if values := o.Values("missing"); values != nil {
+		t.Error(values)
+	}
+
+	o.Set("/role", "guest")
+	if values := o.Values("role"); len(values) != 1 {
+		t.Error(values)
+	}
+
+	plain, _ := NewObjectFromBytes([]byte(duplicateDoc))
+	if values := plain.Values("role"); len(values) != 1 {
+		t.Error(values)
+	}
+}
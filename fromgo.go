@@ -0,0 +1,232 @@
+package jason
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+var (
+	marshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Converts a Go value to a value, like encoding/json would marshal it and NewValueFromBytes parse it,
+// but without going through JSON text. Numbers become json.Number, struct fields follow their json tags
+// and json.Marshaler and encoding.TextMarshaler are used where implemented.
+// Example:
+//
+//	want, err := jason.FromGo(map[string]interface{}{"name": "dave", "age": 30})
+//	if !got.Equal(want) {
+//		...
+//	}
+func FromGo(x interface{}) (*Value, error) {
+	data, err := toData(x)
+	if err != nil {
+		return nil, err
+	}
+	return &Value{data: data, exists: true}, nil
+}
+
+// Converts a Go value that marshals to a JSON object, like a struct or a map, to an object. See FromGo.
+// Example:
+//
+//	o, err := jason.FromGoObject(person)
+//	name, err := o.GetString("name")
+func FromGoObject(x interface{}) (*Object, error) {
+	return objectFromValue(FromGo(x))
+}
+
+// Converts what toData has no shortcut for, following the rules of encoding/json.
+// Depth guards against cyclic pointers, which encoding/json rejects too.
+func reflectData(rv reflect.Value, depth int) (interface{}, error) {
+	if !rv.IsValid() {
+		return nil, nil
+	}
+	if depth > DefaultMaxDepth {
+		return nil, &json.UnsupportedValueError{Value: rv, Str: "encountered a cycle via " + rv.Type().String()}
+	}
+
+	t := rv.Type()
+	if (t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface) && rv.IsNil() {
+		return nil, nil
+	}
+	switch t {
+	case reflect.TypeOf(&Value{}), reflect.TypeOf(&Object{}), reflect.TypeOf(json.Number("")):
+		return toData(rv.Interface())
+	}
+	if rv.Kind() != reflect.Ptr && rv.CanAddr() && reflect.PtrTo(t).Implements(marshalerType) {
+		rv = rv.Addr()
+	}
+	if m, ok := rv.Interface().(json.Marshaler); ok {
+		b, err := m.MarshalJSON()
+		if err != nil {
+			return nil, &json.MarshalerError{Type: t, Err: err}
+		}
+		v, err := ParseOptions{Strict: true}.NewValueFromBytes(b)
+		if err != nil {
+			return nil, &json.MarshalerError{Type: t, Err: err}
+		}
+		return v.raw(), nil
+	}
+	if rv.Kind() != reflect.Ptr && rv.CanAddr() && reflect.PtrTo(t).Implements(textMarshalerType) {
+		rv = rv.Addr()
+	}
+	if m, ok := rv.Interface().(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		if err != nil {
+			return nil, &json.MarshalerError{Type: t, Err: err}
+		}
+		return string(b), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return reflectData(rv.Elem(), depth+1)
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return json.Number(strconv.FormatInt(rv.Int(), 10)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return json.Number(strconv.FormatUint(rv.Uint(), 10)), nil
+	case reflect.Float32, reflect.Float64:
+		return floatData(rv.Float(), t.Bits())
+	case reflect.Map:
+		return mapData(rv, depth)
+	case reflect.Slice:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 && !reflect.PtrTo(t.Elem()).Implements(marshalerType) &&
+			!reflect.PtrTo(t.Elem()).Implements(textMarshalerType) {
+			return base64.StdEncoding.EncodeToString(rv.Bytes()), nil
+		}
+		fallthrough
+	case reflect.Array:
+		s := make([]interface{}, rv.Len())
+		for i := range s {
+			data, err := reflectData(rv.Index(i), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			s[i] = data
+		}
+		return s, nil
+	case reflect.Struct:
+		return structData(rv, depth)
+	}
+	return nil, &json.UnsupportedTypeError{Type: t}
+}
+
+func mapData(rv reflect.Value, depth int) (interface{}, error) {
+	if rv.IsNil() {
+		return nil, nil
+	}
+	t := rv.Type()
+	m := make(map[string]interface{}, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		var key string
+		k := iter.Key()
+		switch {
+		case k.Kind() == reflect.String:
+			key = k.String()
+		case t.Key().Implements(textMarshalerType):
+			if k.Kind() == reflect.Ptr && k.IsNil() {
+				break
+			}
+			b, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return nil, &json.MarshalerError{Type: t.Key(), Err: err}
+			}
+			key = string(b)
+		case k.CanInt():
+			key = strconv.FormatInt(k.Int(), 10)
+		case k.CanUint():
+			key = strconv.FormatUint(k.Uint(), 10)
+		default:
+			return nil, &json.UnsupportedTypeError{Type: t}
+		}
+		data, err := reflectData(iter.Value(), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = data
+	}
+	return m, nil
+}
+
+func structData(rv reflect.Value, depth int) (interface{}, error) {
+	fields := structFields(rv.Type())
+	m := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		field, ok := fieldValue(rv, f.index)
+		if !ok || (f.omitEmpty && isEmptyValue(field)) {
+			continue
+		}
+		data, err := reflectData(field, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if f.quoted {
+			data = quoteData(field, data)
+		}
+		m[f.name] = data
+	}
+	return m, nil
+}
+
+// Returns the field at the index, or false if it is inside a nil embedded pointer.
+func fieldValue(rv reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				return reflect.Value{}, false
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, true
+}
+
+// Applies the ",string" option, which only affects strings, numbers and booleans.
+func quoteData(field reflect.Value, data interface{}) interface{} {
+	for field.Kind() == reflect.Ptr && !field.IsNil() {
+		field = field.Elem()
+	}
+	switch field.Kind() {
+	case reflect.String:
+		if s, ok := data.(string); ok {
+			b, _ := json.Marshal(s)
+			return string(b)
+		}
+	case reflect.Bool:
+		return fmt.Sprint(data)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		if n, ok := data.(json.Number); ok {
+			return string(n)
+		}
+	}
+	return data
+}
+
+// Reports whether omitempty leaves the value out, like encoding/json.
+func isEmptyValue(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Ptr:
+		return rv.IsZero()
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package jason
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fromGoItem struct {
+	Name    string            `json:"name"`
+	Count   int64             `json:"count,omitempty"`
+	Price   float64           `json:"price,string"`
+	Note    *string           `json:"note"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	skipped int
+	Ignored bool `json:"-"`
+}
+
+func TestFromGo(t *testing.T) {
+	o, _ := NewObjectFromBytes([]byte(personDoc))
+	var p decodePerson
+	if err := o.GetInto(&p, "person"); err != nil {
+		t.Fatal(err)
+	}
+	note := "n"
+
+	cases := []interface{}{
+		nil, true, "text", 42, uint8(7), -1.5, float32(0.1), json.Number("1e3"),
+		[]interface{}{1, "a", nil},
+		map[string]interface{}{"a": []int{1, 2}},
+		map[int]string{1: "one", -2: "two"},
+		map[time.Time]int{time.Unix(0, 0).UTC(): 1},
+		[]byte("bytes"), [3]bool{true}, []string(nil),
+		time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		fromGoItem{Name: "a", Price: 2.5},
+		&fromGoItem{Name: "b", Count: 3, Note: &note, Labels: map[string]string{"k": "v"}, skipped: 1, Ignored: true},
+		[]*fromGoItem{nil, {}},
+		p,
+	}
+	for _, x := range cases {
+		got, err := FromGo(x)
+		if err != nil {
+			t.Errorf("%#v: %v", x, err)
+			continue
+		}
+		b, err := json.Marshal(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := NewValueFromBytes(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(want) {
+			g, _ := got.Marshal()
+			t.Errorf("FromGo(%#v) = %s, want %s", x, g, b)
+		}
+	}
+}
+
+func TestFromGoNumbers(t *testing.T) {
+	type small int8
+	v, err := FromGo(struct {
+		A small
+		B float32
+		C uint64
+	}{-3, 0.1, math.MaxUint64})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"A": json.Number("-3"), "B": json.Number("0.1"), "C": json.Number("18446744073709551615")}
+	if !reflect.DeepEqual(v.raw(), want) {
+		t.Errorf("got %#v, want %#v", v.raw(), want)
+	}
+}
+
+func TestFromGoObject(t *testing.T) {
+	o, err := FromGoObject(fromGoItem{Name: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name, err := o.GetString("name"); err != nil || name != "a" {
+		t.Errorf("name = %q, %v", name, err)
+	}
+	if _, err := FromGoObject([]int{1}); !errors.Is(err, ErrNotObject) {
+		t.Errorf("error for array = %v, want ErrNotObject", err)
+	}
+}
+
+func TestFromGoErrors(t *testing.T) {
+	type cyclic struct {
+		Next *cyclic
+	}
+	loop := &cyclic{}
+	loop.Next = loop
+
+	cases := []struct {
+		x    interface{}
+		want interface{}
+	}{
+		{make(chan int), &json.UnsupportedTypeError{}},
+		{map[float64]int{1: 1}, &json.UnsupportedTypeError{}},
+		{[]interface{}{func() {}}, &json.UnsupportedTypeError{}},
+		{loop, &json.UnsupportedValueError{}},
+		{json.RawMessage(`{`), &json.MarshalerError{}},
+	}
+	for _, c := range cases {
+		_, err := FromGo(c.x)
+		if err == nil || reflect.TypeOf(err) != reflect.TypeOf(c.want) {
+			t.Errorf("FromGo(%T) error = %v, want %T", c.x, err, c.want)
+		}
+	}
+	if _, err := FromGo(math.Inf(1)); err == nil {
+		t.Error("FromGo(+Inf) succeeded")
+	}
+}
+
+func TestFromGoMarshalsLikeEncodingJSON(t *testing.T) {
+	type measure struct {
+		Value float64 `json:"value"`
+		Small float32 `json:"small"`
+		Ratio float64 `json:"ratio,string"`
+	}
+	cases := []interface{}{
+		map[string]interface{}{"n": 1e6},
+		[]float64{1e20, 1e21, 1e-6, 1e-7, 123456789.125},
+		measure{Value: 3e6, Small: 1e-7, Ratio: 2e6},
+		[]measure{{Value: -0.5}},
+	}
+	for _, x := range cases {
+		v, err := FromGo(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := v.Marshal()
+		b, _ := json.Marshal(x)
+		parsed, err := NewValueFromBytes(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Parsing sorts the members of structs like FromGo does
+		want, _ := parsed.Marshal()
+		if string(got) != string(want) {
+			t.Errorf("FromGo(%v) marshals to %s, want %s", x, got, want)
+		}
+	}
+
+	v, _ := FromGo(map[string]interface{}{"n": 1e6})
+	if n, err := v.Get("n").Int64(); err != nil || n != 1000000 {
+		t.Errorf("Int64() = %d, %v", n, err)
+	}
+
+	m := map[string]interface{}{}
+	m["self"] = m
+	type node struct {
+		Next interface{}
+	}
+	n := &node{}
+	n.Next = n
+	for _, x := range []interface{}{m, n, []interface{}{n}} {
+		var e *json.UnsupportedValueError
+		if _, err := FromGo(x); !errors.As(err, &e) {
+			t.Errorf("FromGo(%T) error = %v, want *json.UnsupportedValueError", x, err)
+		}
+		if _, err := json.Marshal(x); !errors.As(err, &e) {
+			t.Errorf("json.Marshal(%T) error = %v", x, err)
+		}
+	}
+}
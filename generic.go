@@ -0,0 +1,172 @@
+package jason
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ErrUnsupportedType is returned by Get and As for types they can't convert to.
+var ErrUnsupportedType = errors.New("unsupported type")
+
+// Unmarshaler is implemented by types that read themselves from a value, see As.
+type Unmarshaler interface {
+	UnmarshalJason(v *Value) error
+}
+
+// Gets the value at the path, made of member names and array indices, and converts it to T. See As.
+// Example:
+//
+//	port, err := jason.Get[uint16](v, "server", "port")
+//	first, err := jason.Get[string](v, "friends", 0, "name")
+//	name, err := jason.Get[string](&o.Value, "name")
+func Get[T any](v *Value, path ...any) (T, error) {
+	for _, key := range path {
+		v = v.Get(key)
+	}
+	return As[T](v)
+}
+
+// Gets the array at the path and converts every element to T. See Get.
+// Example:
+//
+//	ids, err := jason.GetArray[int64](v, "ids")
+func GetArray[T any](v *Value, path ...any) ([]T, error) {
+	for _, key := range path {
+		v = v.Get(key)
+	}
+	elements, err := v.Array()
+	if err != nil {
+		return nil, err
+	}
+	s := make([]T, len(elements))
+	for i, element := range elements {
+		if s[i], err = As[T](element); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Converts the value to T.
+// T may be string, bool, any int, uint or float type, json.Number, *Object, *Value, time.Time in RFC 3339
+// format, or a type whose pointer implements Unmarshaler or json.Unmarshaler.
+// Numbers that don't fit into T are reported like by Int64.
+// Example:
+//
+//	level, err := jason.As[int8](v.Get("level"))
+func As[T any](v *Value) (T, error) {
+	var result T
+	if v == nil {
+		return result, &PathError{Err: ErrNilValue}
+	}
+	if v.Err != nil {
+		return result, v.Err
+	}
+
+	var err error
+	switch r := any(&result).(type) {
+	case *string:
+		*r, err = v.String()
+	case *bool:
+		*r, err = v.Boolean()
+	case *int:
+		*r, err = asInt[int](v, strconv.IntSize)
+	case *int8:
+		*r, err = asInt[int8](v, 8)
+	case *int16:
+		*r, err = asInt[int16](v, 16)
+	case *int32:
+		*r, err = asInt[int32](v, 32)
+	case *int64:
+		*r, err = v.Int64()
+	case *uint:
+		*r, err = asUint[uint](v, strconv.IntSize)
+	case *uint8:
+		*r, err = asUint[uint8](v, 8)
+	case *uint16:
+		*r, err = asUint[uint16](v, 16)
+	case *uint32:
+		*r, err = asUint[uint32](v, 32)
+	case *uint64:
+		*r, err = asUint[uint64](v, 64)
+	case *float32:
+		var n json.Number
+		if n, err = v.Number(); err == nil {
+			var f float64
+			f, err = strconv.ParseFloat(string(n), 32)
+			if err != nil {
+				err = v.typeError(KindNumber, err)
+			}
+			*r = float32(f)
+		}
+	case *float64:
+		*r, err = v.Float64()
+	case *json.Number:
+		*r, err = v.Number()
+	case **Object:
+		*r, err = v.Object()
+	case **Value:
+		*r = v
+	case *time.Time:
+		var s string
+		if s, err = v.String(); err == nil {
+			if *r, err = time.Parse(time.RFC3339Nano, s); err != nil {
+				err = v.valueError(err)
+			}
+		}
+	case Unmarshaler:
+		if err = r.UnmarshalJason(v); err != nil {
+			err = v.valueError(err)
+		}
+	case json.Unmarshaler:
+		var b []byte
+		if b, err = v.Marshal(); err == nil {
+			if err = r.UnmarshalJSON(b); err != nil {
+				err = v.valueError(err)
+			}
+		}
+	default:
+		err = fmt.Errorf("%w %T", ErrUnsupportedType, result)
+	}
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return result, nil
+}
+
+func asInt[T int | int8 | int16 | int32](v *Value, bits int) (T, error) {
+	n, err := v.Number()
+	if err != nil {
+		return 0, err
+	}
+	i, err := strconv.ParseInt(string(n), 10, bits)
+	if err != nil {
+		return 0, v.typeError(KindNumber, err)
+	}
+	return T(i), nil
+}
+
+func asUint[T uint | uint8 | uint16 | uint32 | uint64](v *Value, bits int) (T, error) {
+	n, err := v.Number()
+	if err != nil {
+		return 0, err
+	}
+	i, err := strconv.ParseUint(string(n), 10, bits)
+	if err != nil {
+		return 0, v.typeError(KindNumber, err)
+	}
+	return T(i), nil
+}
+
+// Error for a value that has the right kind but can't be converted.
+func (v *Value) valueError(err error) error {
+	var e *PathError
+	if errors.As(err, &e) {
+		return err
+	}
+	return &PathError{Path: v.path(), Err: err, Position: v.Position()}
+}
@@ -0,0 +1,123 @@
+package jason
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type celsius float64
+
+func (c *celsius) UnmarshalJason(v *Value) error {
+	s, err := v.String()
+	if err != nil {
+		return err
+	}
+	f, err := strconv.ParseFloat(strings.TrimSuffix(s, "C"), 64)
+	*c = celsius(f)
+	return err
+}
+
+type point struct {
+	X, Y int
+}
+
+func TestGet(t *testing.T) {
+	v, _ := NewValueFromBytes([]byte(`{
+		"name": "anton", "ok": true, "small": -128, "big": 18446744073709551615,
+		"ratio": 0.5, "when": "2024-03-01T12:00:00Z", "temp": "21.5C",
+		"at": {"X": 1, "Y": 2}, "list": [{"id": 1}, {"id": 2}], "ids": [3, 4, 5]
+	}`))
+
+	check := func(got interface{}, err error, want interface{}) {
+		t.Helper()
+		if err != nil || got != want {
+			t.Errorf("got %v (%T), %v, want %v", got, got, err, want)
+		}
+	}
+	s, err := Get[string](v, "name")
+	check(s, err, "anton")
+	b, err := Get[bool](v, "ok")
+	check(b, err, true)
+	i8, err := Get[int8](v, "small")
+	check(i8, err, int8(-128))
+	u64, err := Get[uint64](v, "big")
+	check(u64, err, uint64(18446744073709551615))
+	f32, err := Get[float32](v, "ratio")
+	check(f32, err, float32(0.5))
+	n, err := Get[json.Number](v, "ratio")
+	check(n, err, json.Number("0.5"))
+	id, err := Get[int](v, "list", 1, "id")
+	check(id, err, 2)
+	when, err := Get[time.Time](v, "when")
+	check(when, err, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	temp, err := Get[celsius](v, "temp")
+	check(temp, err, celsius(21.5))
+	at, err := Get[point](v, "at")
+	if err == nil {
+		t.Error("point has no unmarshal method")
+	}
+	check(at, nil, point{})
+
+	o, err := Get[*Object](v, "list", 0)
+	if err != nil || o.Map()["id"] == nil {
+		t.Error(o, err)
+	}
+	value, err := Get[*Value](v, "list", -1)
+	if err != nil || value.path() != "/list/1" {
+		t.Error(value, err)
+	}
+	object, _ := NewObjectFromBytes([]byte(`{"name": "anton"}`))
+	if name, err := Get[string](&object.Value, "name"); err != nil || name != "anton" {
+		t.Error(name, err)
+	}
+}
+
+func TestGetErrors(t *testing.T) {
+	v, _ := NewValueFromBytes([]byte(`{"small": -129, "neg": -1, "big": 1e40, "frac": 1.5, "name": "x", "when": "yesterday", "list": [1, "two"]}`))
+	cases := []struct {
+		err  error
+		path string
+		want error
+	}{
+		{second(Get[int8](v, "small")), "/small", strconv.ErrRange},
+		{second(Get[uint](v, "neg")), "/neg", strconv.ErrSyntax},
+		{second(Get[float32](v, "big")), "/big", strconv.ErrRange},
+		{second(Get[int32](v, "frac")), "/frac", strconv.ErrSyntax},
+		{second(Get[int](v, "name")), "/name", ErrNotNumber},
+		{second(Get[bool](v, "missing")), "/missing", nil},
+		{second(Get[string](v, "list", 5)), "/list/5", ErrIndexOutOfRange},
+		{second(Get[time.Time](v, "when")), "/when", nil},
+		{second(GetArray[int](v, "list")), "/list/1", ErrNotNumber},
+		{second(GetArray[int](v, "name")), "/name", ErrNotArray},
+	}
+	for i, c := range cases {
+		var e *PathError
+		if !errors.As(c.err, &e) || e.Path != c.path || (c.want != nil && !errors.Is(c.err, c.want)) {
+			t.Errorf("%d: got %v", i, c.err)
+		}
+	}
+
+	if _, err := Get[complex128](v, "frac"); !errors.Is(err, ErrUnsupportedType) {
+		t.Error(err)
+	}
+	if _, err := As[string](nil); !errors.Is(err, ErrNilValue) {
+		t.Error(err)
+	}
+}
+
+func TestGetArray(t *testing.T) {
+	v, _ := NewValueFromBytes([]byte(`{"ids": [3, 4, 5], "names": ["a", "b"], "empty": []}`))
+	if ids, err := GetArray[uint8](v, "ids"); err != nil || len(ids) != 3 || ids[2] != 5 {
+		t.Error(ids, err)
+	}
+	if names, err := GetArray[string](v, "names"); err != nil || strings.Join(names, ",") != "a,b" {
+		t.Error(names, err)
+	}
+	if empty, err := GetArray[float64](v, "empty"); err != nil || empty == nil || len(empty) != 0 {
+		t.Error(empty, err)
+	}
+}
@@ -1,6 +1,6 @@
 module github.com/aimof/jason
 
-go 1.12
+go 1.18
 
 require (
 	golang.org/x/arch v0.0.0-20190312162104-788fe5ffcd8c // indirect
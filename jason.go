@@ -3,7 +3,7 @@
 
 // Jason is designed to be convenient for reading arbitrary JSON while still honoring the strictness of the language.
 // Inspired by other libraries and improved to work well for common use cases.
-// It currently focuses on reading JSON data rather than creating it.
+// It focuses on reading JSON data, but documents can also be created with NewObjectBuilder.
 //
 // Examples
 //
@@ -47,12 +47,12 @@
 package jason
 
 import (
-	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"reflect"
+	"strconv"
+	"sync/atomic"
 )
 
 // Error values returned when validation functions fail
@@ -64,6 +64,8 @@ var (
 	ErrNotObject      = errors.New("not an object")
 	ErrNotObjectArray = errors.New("not an object array")
 	ErrNotString      = errors.New("not a string")
+	ErrNilValue       = errors.New("value is nil")
+	ErrInvalidKey     = errors.New("invalid key")
 )
 
 type KeyNotFoundError struct {
@@ -78,12 +80,46 @@ func (k KeyNotFoundError) Error() string {
 	return "key not found"
 }
 
+// PathError is returned when a lookup or type conversion fails.
+// Path is the JSON Pointer of the failing value relative to the document root.
+// Expected and Actual are set when the value has the wrong type, Err is the
+// underlying error, e.g. ErrNotString or KeyNotFoundError, and can be matched with errors.Is and errors.As.
+// Position is where the failing value, or the object or array a missing key was looked up in, starts in the source.
+type PathError struct {
+	Path     string
+	Expected Kind
+	Actual   Kind
+	Err      error
+	Position Position
+}
+
+func (e *PathError) Error() string {
+	msg := fmt.Sprintf("path %q: %v", e.Path, e.Err)
+	if e.Position.IsValid() {
+		msg = fmt.Sprintf("%s: %s", e.Position, msg)
+	}
+	if e.Expected != KindInvalid && e.Actual != e.Expected {
+		msg += fmt.Sprintf(" (expected %s, got %s)", e.Expected, e.Actual)
+	}
+	return msg
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
 // Value represents an arbitrary JSON value.
 // It may contain a bool, number, string, object, array or null.
 type Value struct {
 	data   interface{}
-	exists bool  // Used to separate nil and non-existing values
-	Err    error // True when the value is invalid.
+	exists bool   // Used to separate nil and non-existing values
+	Err    error  // True when the value is invalid.
+	parent *Value // The value this one was read from, to report the path in errors
+	key    string // The reference token of this value in its parent
+	node   *parseNode
+	lazy   *lazyValue // Set until a value created by NewLazyValue is parsed
+	coerce coercion   // Set by Lenient and Strict
+	object *Object    // The Object this value is part of, whose map is forgotten when a member changes
 }
 
 // Object represents an object JSON object.
@@ -91,7 +127,7 @@ type Value struct {
 // a map representation of it's content. It's useful when iterating.
 type Object struct {
 	Value
-	m     map[string]*Value
+	m     atomic.Value // *map[string]*Value, built on first use by Map
 	valid bool
 }
 
@@ -100,15 +136,111 @@ type Array struct {
 	s []*Value
 }
 
+// Kind is the type of a JSON value.
+type Kind int
+
+const (
+	KindInvalid Kind = iota
+	KindNull
+	KindBool
+	KindNumber
+	KindString
+	KindArray
+	KindObject
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindNull:
+		return "null"
+	case KindBool:
+		return "bool"
+	case KindNumber:
+		return "number"
+	case KindString:
+		return "string"
+	case KindArray:
+		return "array"
+	case KindObject:
+		return "object"
+	}
+	return "invalid"
+}
+
+// Returns the kind of the value, or KindInvalid if the value has an error.
+func (v *Value) Kind() Kind {
+	if v.Err != nil {
+		return KindInvalid
+	}
+	if v.lazy != nil {
+		return v.lazy.kind()
+	}
+	return kindOf(v.raw())
+}
+
+func kindOf(data interface{}) Kind {
+	switch data.(type) {
+	case nil:
+		return KindNull
+	case bool:
+		return KindBool
+	case json.Number:
+		return KindNumber
+	case string:
+		return KindString
+	case []interface{}:
+		return KindArray
+	case map[string]interface{}, *Object:
+		return KindObject
+	}
+	return KindInvalid
+}
+
 // Marshal into bytes.
 func (v *Object) MarshalJSON() ([]byte, error) {
-	return json.Marshal(v.m)
+	if node := v.orderedNode(); node != nil {
+		return marshalOrdered(v.raw(), node)
+	}
+	return json.Marshal(v.raw())
 }
 
 // Returns the golang map.
 // Needed when iterating through the values of the object.
+// The map is built on first use and reused until the object, or a value read from it, changes.
 func (v *Object) Map() map[string]*Value {
-	return v.m
+	if v.object != v {
+		// A copy of an Object isn't told about changes, so it builds the map every time
+		return v.buildMap()
+	}
+	loaded := v.m.Load()
+	if cached, _ := loaded.(*map[string]*Value); cached != nil {
+		return *cached
+	}
+
+	m := v.buildMap()
+	if m == nil {
+		return nil
+	}
+	if !v.m.CompareAndSwap(loaded, &m) {
+		// Another goroutine built it first
+		return v.Map()
+	}
+	return m
+}
+
+func (v *Object) buildMap() map[string]*Value {
+	if v.lazy != nil {
+		return v.lazyMap()
+	}
+	data, ok := v.data.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	m := make(map[string]*Value, len(data))
+	for key, element := range data {
+		m[key] = v.Value.child(key, element, true)
+	}
+	return m
 }
 
 func NewValue(reader io.Reader) (*Value, error) {
@@ -126,7 +258,7 @@ func dataToObject(src *Value) (dist *Value) {
 		dist := new(Object)
 		for key, v := range src.data.(map[string]interface{}) {
 			if v == nil {
-				dist.m[key] = &Value{data: nil, exists: false}
+				dist.Map()[key] = &Value{data: nil, exists: false}
 			}
 			switch v.(type) {
 			case map[string]interface{}:
@@ -147,48 +279,54 @@ func dataToObject(src *Value) (dist *Value) {
 func (parent *Value) Get(i interface{}) *Value {
 	if parent == nil {
 		return &Value{
-			Err: fmt.Errorf("Get %v: parent is nil", i),
+			Err: &PathError{Err: ErrNilValue},
 		}
 	}
 	if parent.Err != nil {
 		return &*parent
 	}
+	if parent.lazy != nil {
+		return parent.lazyGet(i)
+	}
 	switch i.(type) {
 	case string:
-		switch parent.Interface().(type) {
+		switch parent.raw().(type) {
 		case map[string]interface{}:
-			child, ok := parent.Interface().(map[string]interface{})[i.(string)]
+			child, ok := parent.raw().(map[string]interface{})[i.(string)]
 			if !ok {
-				return &Value{Err: fmt.Errorf("Get: %v is not in keys", i)}
+				return &Value{Err: parent.lookupError(i.(string), KeyNotFoundError{i.(string)})}
 			}
-			if child == nil {
-				return &Value{data: nil, exists: false}
-			}
-			return &Value{data: child, exists: true}
+			return parent.child(i.(string), child, child != nil)
 		default:
-			parent.Err = fmt.Errorf("Get %v: parent is not an object", i)
+			return &Value{Err: parent.typeError(KindObject, ErrNotObject)}
 		}
 	case int:
-		switch parent.Interface().(type) {
+		switch parent.raw().(type) {
 		case []interface{}:
-			if i.(int) < len(parent.Interface().([]interface{})) {
-				child := parent.Interface().([]interface{})[i.(int)]
-				if child == nil {
-					return &Value{data: nil, exists: false}
-				}
-				return &Value{data: child, exists: true}
+			index := i.(int)
+			if index < 0 {
+				index += len(parent.raw().([]interface{}))
 			}
-			return &Value{Err: fmt.Errorf("Get %v: Index out of range", i)}
+			if index >= 0 && index < len(parent.raw().([]interface{})) {
+				child := parent.raw().([]interface{})[index]
+				return parent.child(strconv.Itoa(index), child, child != nil)
+			}
+			return &Value{Err: parent.lookupError(strconv.Itoa(i.(int)), ErrIndexOutOfRange)}
+		default:
+			return &Value{Err: parent.typeError(KindArray, ErrNotArray)}
 		}
 	}
-	return &Value{Err: fmt.Errorf("Get: %v is invalid", i)}
+	return &Value{Err: &PathError{Path: parent.path(), Err: ErrInvalidKey, Position: parent.Position()}}
 }
 
 func (parent *Value) GetAll() (map[string]*Value, error) {
+	if err := parent.load(); err != nil {
+		return nil, err
+	}
 	switch parent.data.(type) {
 	case map[string]interface{}:
 	default:
-		return nil, fmt.Errorf("GetAll: value is not an object, type: %v", reflect.TypeOf(parent.data))
+		return nil, parent.typeError(KindObject, ErrNotObject)
 	}
 
 	children := make(map[string]*Value, len(parent.data.(map[string]interface{})))
@@ -207,7 +345,14 @@ func (parent *Value) GetAll() (map[string]*Value, error) {
 // Useful for parsing the body of a net/http response.
 // Example: NewFromReader(res.Body)
 func NewValueFromReader(reader io.Reader) (*Value, error) {
-	j, err := newValueFromReader(reader)
+	return rootValue(newValueFromReader(reader))
+}
+
+// NewValueFromReader and NewValueFromBytes store a root object as *Object.
+func rootValue(j *Value, err error) (*Value, error) {
+	if err != nil {
+		return nil, err
+	}
 	switch j.Interface().(type) {
 	case map[string]interface{}:
 		j.data, err = objectFromValue(j, err)
@@ -219,19 +364,14 @@ func NewValueFromReader(reader io.Reader) (*Value, error) {
 }
 
 func newValueFromReader(reader io.Reader) (*Value, error) {
-	j := new(Value)
-	d := json.NewDecoder(reader)
-	d.UseNumber()
-	err := d.Decode(&j.data)
-	return j, err
+	return ParseOptions{}.NewValue(reader)
 }
 
 // Duplicated
 // Creates a new value from bytes.
 // Returns an error if the bytes are not valid json.
 func NewValueFromBytes(b []byte) (*Value, error) {
-	r := bytes.NewReader(b)
-	return NewValueFromReader(r)
+	return rootValue(ParseOptions{}.NewValueFromBytes(b))
 }
 
 func objectFromValue(v *Value, err error) (*Object, error) {
@@ -249,7 +389,7 @@ func objectFromValue(v *Value, err error) (*Object, error) {
 }
 
 func NewObjectFromBytes(b []byte) (*Object, error) {
-	return objectFromValue(newValueFromReader(bytes.NewReader(b)))
+	return ParseOptions{}.NewObjectFromBytes(b)
 }
 
 func NewObjectFromReader(reader io.Reader) (*Object, error) {
@@ -258,6 +398,12 @@ func NewObjectFromReader(reader io.Reader) (*Object, error) {
 
 // Marshal into bytes.
 func (v *Value) Marshal() ([]byte, error) {
+	if err := v.load(); err != nil {
+		return nil, err
+	}
+	if node := v.orderedNode(); node != nil {
+		return marshalOrdered(v.raw(), node)
+	}
 	return json.Marshal(v.data)
 }
 
@@ -268,25 +414,113 @@ func (v *Value) MarshalJSON() ([]byte, error) {
 
 // Get the interyling data as interface
 func (v *Value) Interface() interface{} {
+	v.load()
+	return v.data
+}
+
+// Private data accessor.
+// NewValueFromReader stores a root object as *Object, so unwrap it to reach the map.
+func (v *Value) raw() interface{} {
+	v.load()
+	if o, ok := v.data.(*Object); ok {
+		return o.data
+	}
 	return v.data
 }
 
+// Returns the JSON Pointer of the value relative to the root it was read from.
+func (v *Value) path() string {
+	var tokens []string
+	for current := v; current.parent != nil; current = current.parent {
+		tokens = append(tokens, current.key)
+	}
+	for i, j := 0, len(tokens)-1; i < j; i, j = i+1, j-1 {
+		tokens[i], tokens[j] = tokens[j], tokens[i]
+	}
+	return formatPointer(tokens)
+}
+
+// Creates the value of a member or element read from v.
+func (v *Value) child(key string, data interface{}, exists bool) *Value {
+	return &Value{data: data, exists: exists, parent: v, key: key}
+}
+
+// Error for a key that can't be looked up in v.
+func (v *Value) lookupError(key string, err error) error {
+	return &PathError{Path: v.path() + "/" + escapePointerToken(key), Err: err, Position: v.Position()}
+}
+
+// Error for a value that doesn't have the expected kind.
+// A value that couldn't be read keeps the error that caused it.
+func (v *Value) typeError(expected Kind, err error) error {
+	if v.Err != nil {
+		return v.Err
+	}
+	return &PathError{Path: v.path(), Expected: expected, Actual: v.Kind(), Err: err, Position: v.Position()}
+}
+
 // Private Get
 func (v *Value) get(key string) (*Value, error) {
+	if v.lazy != nil {
+		return v.lazyGetKey(key)
+	}
 
-	// Assume this is an object
-	obj, err := v.Object()
-
-	if err == nil {
-		child, ok := obj.Map()[key]
-		if ok {
-			return child, nil
-		} else {
-			return nil, KeyNotFoundError{key}
+	switch data := v.raw().(type) {
+	case []interface{}:
+		// Keys index into arrays, negative indices count from the end
+		i, err := arrayIndex(key, len(data))
+		if err == ErrNotObject {
+			return nil, v.typeError(KindObject, err)
+		} else if err != nil {
+			return nil, v.lookupError(key, err)
+		}
+		return v.child(strconv.Itoa(i), data[i], true), nil
+	case map[string]interface{}:
+		// Look the key up directly, building the map of an Object would touch every member
+		child, ok := data[key]
+		if !ok {
+			return nil, v.lookupError(key, KeyNotFoundError{key})
 		}
+		return v.child(key, child, true), nil
 	}
 
-	return nil, err
+	return nil, v.typeError(KindObject, ErrNotObject)
+}
+
+// Parses an array index key.
+// Returns ErrNotObject for keys that aren't integers, since only objects have named keys.
+func arrayIndex(key string, length int) (int, error) {
+	i, err := strconv.Atoi(key)
+	if err != nil {
+		return 0, ErrNotObject
+	}
+	if i < 0 {
+		i += length
+	}
+	if i < 0 || i >= length {
+		return 0, ErrIndexOutOfRange
+	}
+	return i, nil
+}
+
+// Builds a key path that mixes object keys and array indices.
+// Integers are formatted as array indices, negative ones count from the end of the array.
+// Example:
+//		name, err := GetString(jason.Path("friends", 0, "name")...)
+//		last, err := GetString(jason.Path("friends", -1, "name")...)
+func Path(elems ...interface{}) []string {
+	keys := make([]string, len(elems))
+	for i, elem := range elems {
+		switch elem := elem.(type) {
+		case string:
+			keys[i] = elem
+		case int:
+			keys[i] = strconv.Itoa(elem)
+		default:
+			keys[i] = fmt.Sprint(elem)
+		}
+	}
+	return keys
 }
 
 // Private get path
@@ -717,6 +951,9 @@ func (v *Object) GetNullArray(keys ...string) (int64, error) {
 
 // Returns an error if the value is not actually null
 func (v *Value) Null() error {
+	if err := v.load(); err != nil {
+		return err
+	}
 	var valid bool
 
 	// Check the type of this data
@@ -730,7 +967,7 @@ func (v *Value) Null() error {
 		return nil
 	}
 
-	return ErrNotNull
+	return v.typeError(KindNull, ErrNotNull)
 
 }
 
@@ -739,6 +976,12 @@ func (v *Value) Null() error {
 // Example:
 //		friendsArray, err := friendsValue.Array()
 func (v *Value) Array() ([]*Value, error) {
+	if v.lazy != nil && v.lazy.kind() == KindArray {
+		return v.lazyArray()
+	}
+	if err := v.load(); err != nil {
+		return nil, err
+	}
 	var valid bool
 
 	// Check the type of this data
@@ -753,15 +996,25 @@ func (v *Value) Array() ([]*Value, error) {
 
 	if valid {
 
-		for _, element := range v.data.([]interface{}) {
-			child := Value{data: element, exists: true}
-			slice = append(slice, &child)
+		// One allocation for all elements
+		data := v.data.([]interface{})
+		if len(data) > 0 {
+			elements := make([]Value, len(data))
+			slice = make([]*Value, len(data))
+			for i, element := range data {
+				elements[i] = Value{data: element, exists: true, parent: v, key: strconv.Itoa(i)}
+				slice[i] = &elements[i]
+			}
 		}
 
 		return slice, nil
 	}
 
-	return slice, ErrNotArray
+	if v.coerceArray() {
+		return []*Value{v}, nil
+	}
+
+	return slice, v.typeError(KindArray, ErrNotArray)
 
 }
 
@@ -770,6 +1023,9 @@ func (v *Value) Array() ([]*Value, error) {
 // Example:
 //		ageNumber, err := ageValue.Number()
 func (v *Value) Number() (json.Number, error) {
+	if err := v.load(); err != nil {
+		return "", err
+	}
 	var valid bool
 
 	// Check the type of this data
@@ -783,7 +1039,11 @@ func (v *Value) Number() (json.Number, error) {
 		return v.data.(json.Number), nil
 	}
 
-	return "", ErrNotNumber
+	if n, ok := v.coerceNumber(); ok {
+		return n, nil
+	}
+
+	return "", v.typeError(KindNumber, ErrNotNumber)
 }
 
 // Attempts to typecast the current value into a float64.
@@ -797,7 +1057,11 @@ func (v *Value) Float64() (float64, error) {
 		return 0, err
 	}
 
-	return n.Float64()
+	f, err := n.Float64()
+	if err != nil {
+		return 0, v.typeError(KindNumber, err)
+	}
+	return f, nil
 }
 
 // Attempts to typecast the current value into a int64.
@@ -811,7 +1075,11 @@ func (v *Value) Int64() (int64, error) {
 		return 0, err
 	}
 
-	return n.Int64()
+	i, err := n.Int64()
+	if err != nil {
+		return 0, v.typeError(KindNumber, err)
+	}
+	return i, nil
 }
 
 // Attempts to typecast the current value into a bool.
@@ -819,6 +1087,9 @@ func (v *Value) Int64() (int64, error) {
 // Example:
 //		marriedBool, err := marriedValue.Boolean()
 func (v *Value) Boolean() (bool, error) {
+	if err := v.load(); err != nil {
+		return false, err
+	}
 	var valid bool
 
 	// Check the type of this data
@@ -832,7 +1103,11 @@ func (v *Value) Boolean() (bool, error) {
 		return v.data.(bool), nil
 	}
 
-	return false, ErrNotBool
+	if b, ok := v.coerceBoolean(); ok {
+		return b, nil
+	}
+
+	return false, v.typeError(KindBool, ErrNotBool)
 }
 
 // Attempts to typecast the current value into an object.
@@ -840,6 +1115,18 @@ func (v *Value) Boolean() (bool, error) {
 // Example:
 //		friendObject, err := friendValue.Object()
 func (v *Value) Object() (*Object, error) {
+	if v.lazy != nil && v.lazy.kind() == KindObject {
+		// The members are parsed when they are read, but a malformed object is reported here
+		if err := v.lazy.checkMembers(); err != nil {
+			return nil, err
+		}
+		o := &Object{Value: Value{exists: v.exists, parent: v.parent, key: v.key, lazy: v.lazy, coerce: v.coerce}, valid: true}
+		o.object = o
+		return o, nil
+	}
+	if err := v.load(); err != nil {
+		return nil, err
+	}
 
 	var valid bool
 
@@ -853,23 +1140,17 @@ func (v *Value) Object() (*Object, error) {
 	if valid {
 		obj := new(Object)
 		obj.valid = valid
-
-		m := make(map[string]*Value)
-
-		if valid {
-			for key, element := range v.data.(map[string]interface{}) {
-				m[key] = &Value{data: element, exists: true}
-
-			}
-		}
-
 		obj.data = v.data
-		obj.m = m
+		obj.parent = v.parent
+		obj.key = v.key
+		obj.node = v.node
+		obj.coerce = v.coerce
+		obj.object = obj
 
 		return obj, nil
 	}
 
-	return nil, ErrNotObject
+	return nil, v.typeError(KindObject, ErrNotObject)
 }
 
 // Attempts to typecast the current value into an object arrau.
@@ -877,6 +1158,12 @@ func (v *Value) Object() (*Object, error) {
 // Example:
 //		friendObjects, err := friendValues.ObjectArray()
 func (v *Value) ObjectArray() ([]*Object, error) {
+	if v.lazy != nil && v.lazy.kind() == KindArray {
+		return v.lazyObjectArray()
+	}
+	if err := v.load(); err != nil {
+		return nil, err
+	}
 
 	var valid bool
 
@@ -892,20 +1179,31 @@ func (v *Value) ObjectArray() ([]*Object, error) {
 
 	if valid {
 
-		for _, element := range v.data.([]interface{}) {
-			childValue := Value{data: element, exists: true}
-			childObject, err := childValue.Object()
-
-			if err != nil {
-				return nil, ErrNotObjectArray
+		// One allocation for all elements
+		data := v.data.([]interface{})
+		if len(data) > 0 {
+			objects := make([]Object, len(data))
+			slice = make([]*Object, len(data))
+			for i, element := range data {
+				if _, ok := element.(map[string]interface{}); !ok {
+					return nil, v.child(strconv.Itoa(i), element, true).typeError(KindObject, ErrNotObjectArray)
+				}
+				objects[i].data = element
+				objects[i].parent = v
+				objects[i].key = strconv.Itoa(i)
+				objects[i].valid = true
+				slice[i] = &objects[i]
 			}
-			slice = append(slice, childObject)
 		}
 
 		return slice, nil
 	}
 
-	return nil, ErrNotObjectArray
+	if objects, ok := v.coerceObjectArray(); ok {
+		return objects, nil
+	}
+
+	return nil, v.typeError(KindArray, ErrNotObjectArray)
 
 }
 
@@ -914,6 +1212,9 @@ func (v *Value) ObjectArray() ([]*Object, error) {
 // Example:
 //		nameObject, err := nameValue.String()
 func (v *Value) String() (string, error) {
+	if err := v.load(); err != nil {
+		return "", err
+	}
 	var valid bool
 
 	// Check the type of this data
@@ -927,15 +1228,22 @@ func (v *Value) String() (string, error) {
 		return v.data.(string), nil
 	}
 
-	return "", ErrNotString
+	if s, ok := v.coerceString(); ok {
+		return s, nil
+	}
+
+	return "", v.typeError(KindString, ErrNotString)
 }
 
 // Returns the value a json formatted string.
 // Note: The method named String() is used by golang's log method for logging.
 // Example:
 func (v *Object) String() string {
+	if err := v.load(); err != nil {
+		return err.Error()
+	}
 
-	f, err := json.Marshal(v.data)
+	f, err := v.Value.Marshal()
 	if err != nil {
 		return err.Error()
 	}
@@ -3,6 +3,7 @@ package jason
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"log"
 	"reflect"
 	"strings"
@@ -405,37 +406,179 @@ func TestErrors(t *testing.T) {
 		t.Fatal("failed to parse json")
 	}
 
-	if _, err = j.GetObject("string"); err != ErrNotObject {
+	if _, err = j.GetObject("string"); !errors.Is(err, ErrNotObject) {
 		t.Errorf(errstr, "object", err)
 	}
 
-	if err = j.GetNull("string"); err != ErrNotNull {
+	if err = j.GetNull("string"); !errors.Is(err, ErrNotNull) {
 		t.Errorf(errstr, "null", err)
 	}
 
-	if _, err = j.GetStringArray("string"); err != ErrNotArray {
+	if _, err = j.GetStringArray("string"); !errors.Is(err, ErrNotArray) {
 		t.Errorf(errstr, "array", err)
 	}
 
-	if _, err = j.GetStringArray("array"); err != ErrNotString {
+	if _, err = j.GetStringArray("array"); !errors.Is(err, ErrNotString) {
 		t.Errorf(errstr, "string array", err)
 	}
 
-	if _, err = j.GetNumber("array"); err != ErrNotNumber {
+	if _, err = j.GetNumber("array"); !errors.Is(err, ErrNotNumber) {
 		t.Errorf(errstr, "number", err)
 	}
 
-	if _, err = j.GetBoolean("array"); err != ErrNotBool {
+	if _, err = j.GetBoolean("array"); !errors.Is(err, ErrNotBool) {
 		t.Errorf(errstr, "boolean", err)
 	}
 
-	if _, err = j.GetString("number"); err != ErrNotString {
+	if _, err = j.GetString("number"); !errors.Is(err, ErrNotString) {
 		t.Errorf(errstr, "string", err)
 	}
 
 	_, err = j.GetString("not_found")
-	if e, ok := err.(KeyNotFoundError); !ok {
-		t.Errorf(errstr, "key not found error", e)
+	var e KeyNotFoundError
+	if !errors.As(err, &e) {
+		t.Errorf(errstr, "key not found error", err)
 	}
 
 }
+
+func TestPathError(t *testing.T) {
+	j, err := NewObjectFromBytes([]byte(`{
+		"friends": [
+			{"name": "alice", "age": 29},
+			{"name": 42, "age": 1.5}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		err      error
+		path     string
+		expected Kind
+		actual   Kind
+		target   error
+	}{
+		{second(j.GetString("friends", "1", "name")), "/friends/1/name", KindString, KindNumber, ErrNotString},
+		{second(j.GetString(Path("friends", -1, "name")...)), "/friends/1/name", KindString, KindNumber, ErrNotString},
+		{second(j.GetObject("friends", "0", "name")), "/friends/0/name", KindObject, KindString, ErrNotObject},
+		{second(j.GetBoolean("friends", "0", "missing")), "/friends/0/missing", KindInvalid, KindInvalid, KeyNotFoundError{"missing"}},
+		{second(j.GetString("friends", "5")), "/friends/5", KindInvalid, KindInvalid, ErrIndexOutOfRange},
+		{second(j.GetStringArray("friends")), "/friends/0", KindString, KindObject, ErrNotString},
+		{second(j.GetInt64("friends", "1", "age")), "/friends/1/age", KindNumber, KindNumber, nil},
+		{second(j.GetNumber()), "", KindNumber, KindObject, ErrNotNumber},
+		{j.GetNull("friends"), "/friends", KindNull, KindArray, ErrNotNull},
+	}
+	for _, c := range cases {
+		var e *PathError
+		if !errors.As(c.err, &e) {
+			t.Errorf("%v: not a PathError", c.err)
+			continue
+		}
+		if e.Path != c.path || e.Expected != c.expected || e.Actual != c.actual {
+			t.Errorf("%v: got %q %s %s", c.err, e.Path, e.Expected, e.Actual)
+		}
+		if c.target != nil && !errors.Is(c.err, c.target) {
+			t.Errorf("%v: is not %v", c.err, c.target)
+		}
+	}
+
+	friend, _ := j.GetObject("friends", "1")
+	if _, err := friend.GetString("name"); err == nil || err.Error() != `line 4, column 13: path "/friends/1/name": not a string (expected string, got number)` {
+		t.Error(err)
+	}
+
+	v, _ := NewValueFromBytes([]byte(`{"a": [true]}`))
+	if _, err := v.Get("a").Get(0).String(); !errors.Is(err, ErrNotString) || !strings.Contains(err.Error(), `"/a/0"`) {
+		t.Error(err)
+	}
+	if err := v.Get("a").Get("b").Err; !errors.Is(err, ErrNotObject) {
+		t.Error(err)
+	}
+	if v.Get("a").Err != nil {
+		t.Error("parent changed")
+	}
+}
+
+func second(_ interface{}, err error) error {
+	return err
+}
+
+func TestArrayIndexKeys(t *testing.T) {
+	j, err := NewObjectFromBytes([]byte(`{
+		"friends": [
+			{"name": "alice", "tags": ["a", "b"]},
+			{"name": "bob", "ages": [1, 2, 3]}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name, err := j.GetString("friends", "0", "name"); err != nil || name != "alice" {
+		t.Error(name, err)
+	}
+	if name, err := j.GetString(Path("friends", -1, "name")...); err != nil || name != "bob" {
+		t.Error(name, err)
+	}
+	if tag, err := j.GetString(Path("friends", 0, "tags", -2)...); err != nil || tag != "a" {
+		t.Error(tag, err)
+	}
+	if ages, err := j.GetInt64Array(Path("friends", 1, "ages")...); err != nil || len(ages) != 3 {
+		t.Error(ages, err)
+	}
+	if friend, err := j.GetObject("friends", "1"); err != nil {
+		t.Error(err)
+	} else if name, _ := friend.GetString("name"); name != "bob" {
+		t.Error(name)
+	}
+
+	if _, err := j.GetString("friends", "2", "name"); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Error(err)
+	}
+	if _, err := j.GetString("friends", "-3", "name"); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Error(err)
+	}
+	if _, err := j.GetString("friends", "name"); !errors.Is(err, ErrNotObject) {
+		t.Error(err)
+	}
+
+	v, err := NewValue(strings.NewReader(`[1, 2, 3]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := v.Get(-1).Int64(); err != nil || n != 3 {
+		t.Error(n, err)
+	}
+	if v.Get(-4).Err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestObjectMapConcurrentAndCopied(t *testing.T) {
+	o, err := NewObjectFromBytes([]byte(`{"a": 1, "b": {"c": 2}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	maps := make(chan map[string]*Value, 8)
+	for i := 0; i < cap(maps); i++ {
+		go func() { maps <- o.Map() }()
+	}
+	first := <-maps
+	for i := 1; i < cap(maps); i++ {
+		if m := <-maps; m["a"] != first["a"] {
+			t.Error("concurrent calls of Map returned different maps")
+		}
+	}
+
+	// Objects can be copied like before the map was cached
+	copied := *o
+	if n, err := copied.GetInt64("b", "c"); err != nil || n != 2 {
+		t.Error(n, err)
+	}
+	if len(copied.Map()) != 2 {
+		t.Error(copied.Map())
+	}
+}
@@ -0,0 +1,1211 @@
+package jason
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// JSONPath is a compiled JSONPath query (RFC 9535).
+// A JSONPath is safe for concurrent use and can be evaluated against any number of values.
+type JSONPath struct {
+	expr  string
+	query *jpQuery
+}
+
+// JSONPathError is returned when a JSONPath expression can't be compiled.
+// Offset is the byte offset in Expr at which the problem was detected.
+type JSONPathError struct {
+	Expr   string
+	Offset int
+	Msg    string
+}
+
+func (e *JSONPathError) Error() string {
+	return fmt.Sprintf("jsonpath %q: offset %d: %s", e.Expr, e.Offset, e.Msg)
+}
+
+// Compiles a JSONPath expression.
+// Example:
+//
+//	p, err := jason.CompileJSONPath("$.store.book[?@.price < 10].title")
+func CompileJSONPath(expr string) (*JSONPath, error) {
+	p := &jpParser{expr: expr}
+	if !p.consume("$") {
+		return nil, p.errorf("query must start with '$'")
+	}
+	segments, err := p.parseSegments()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.expr) {
+		return nil, p.errorf("unexpected %q", p.expr[p.pos:])
+	}
+	return &JSONPath{expr: expr, query: &jpQuery{segments: segments}}, nil
+}
+
+// Like CompileJSONPath but panics if the expression can't be compiled.
+// Useful for initializing package level variables.
+func MustCompileJSONPath(expr string) *JSONPath {
+	p, err := CompileJSONPath(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Returns the source expression.
+func (p *JSONPath) String() string {
+	return p.expr
+}
+
+// Evaluates the query against v.
+// Returns the selected values in document order together with the normalized path of each match,
+// e.g. $['store']['book'][0]. Object members are visited in document order if v was parsed with
+// PreserveOrder, and in key order otherwise.
+// Example:
+//
+//	values, paths := p.Query(v)
+func (p *JSONPath) Query(v *Value) ([]*Value, []string) {
+	root := &jpNode{data: v.raw()}
+	root.source = v.orderedNode()
+	nodes := p.query.eval(root, root)
+
+	values := make([]*Value, len(nodes))
+	paths := make([]string, len(nodes))
+	for i, n := range nodes {
+		values[i] = n.value(v)
+		paths[i] = n.path()
+	}
+	return values, paths
+}
+
+// Compiles expr and evaluates it against the value.
+// Use CompileJSONPath when the same query is run against many documents.
+// Example:
+//
+//	ids, err := v.Query("$..id")
+func (v *Value) Query(expr string) ([]*Value, error) {
+	p, err := CompileJSONPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	values, _ := p.Query(v)
+	return values, nil
+}
+
+// --- evaluation ---
+
+// A node is a value together with its location in the queried document.
+type jpNode struct {
+	data    interface{}
+	parent  *jpNode
+	key     string
+	index   int
+	isIndex bool
+	source  *parseNode // Gives the order of object members, see Query
+}
+
+// Creates the value of the node, read from the queried value root.
+func (n *jpNode) value(root *Value) *Value {
+	if n.parent == nil {
+		return &Value{data: n.data, exists: true, parent: root.parent, key: root.key, node: root.node, coerce: root.coerce}
+	}
+	key := n.key
+	if n.isIndex {
+		key = strconv.Itoa(n.index)
+	}
+	return n.parent.value(root).child(key, n.data, true)
+}
+
+func (n *jpNode) path() string {
+	var elems []*jpNode
+	for c := n; c.parent != nil; c = c.parent {
+		elems = append(elems, c)
+	}
+
+	var b strings.Builder
+	b.WriteByte('$')
+	for i := len(elems) - 1; i >= 0; i-- {
+		if elems[i].isIndex {
+			b.WriteByte('[')
+			b.WriteString(strconv.Itoa(elems[i].index))
+			b.WriteByte(']')
+		} else {
+			b.WriteString("['")
+			writeNormalizedName(&b, elems[i].key)
+			b.WriteString("']")
+		}
+	}
+	return b.String()
+}
+
+func writeNormalizedName(b *strings.Builder, name string) {
+	for _, r := range name {
+		switch r {
+		case '\'':
+			b.WriteString(`\'`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+}
+
+func (n *jpNode) child(key string) (*jpNode, bool) {
+	m, ok := n.data.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	data, ok := m[key]
+	if !ok {
+		return nil, false
+	}
+	return &jpNode{data: data, parent: n, key: key, source: n.source.child(key)}, true
+}
+
+func (n *jpNode) element(i int) (*jpNode, bool) {
+	s, ok := n.data.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	if i < 0 {
+		i += len(s)
+	}
+	if i < 0 || i >= len(s) {
+		return nil, false
+	}
+	return &jpNode{data: s[i], parent: n, index: i, isIndex: true, source: n.source.child(strconv.Itoa(i))}, true
+}
+
+// Returns the children of an array or object node in order.
+func (n *jpNode) children() []*jpNode {
+	switch data := n.data.(type) {
+	case []interface{}:
+		children := make([]*jpNode, len(data))
+		for i, element := range data {
+			children[i] = &jpNode{data: element, parent: n, index: i, isIndex: true}
+			if n.source != nil && i < len(n.source.children) {
+				children[i].source = n.source.children[i]
+			}
+		}
+		return children
+	case map[string]interface{}:
+		keys := orderedKeys(data, n.source)
+		children := make([]*jpNode, len(keys))
+		for i, key := range keys {
+			children[i] = &jpNode{data: data[key], parent: n, key: key, source: n.source.child(key)}
+		}
+		return children
+	}
+	return nil
+}
+
+type jpQuery struct {
+	relative bool
+	segments []jpSegment
+}
+
+func (q *jpQuery) eval(root, current *jpNode) []*jpNode {
+	nodes := []*jpNode{root}
+	if q.relative {
+		nodes = []*jpNode{current}
+	}
+	for _, segment := range q.segments {
+		var next []*jpNode
+		for _, n := range nodes {
+			if segment.descendant {
+				next = segment.descend(n, root, next)
+			} else {
+				next = segment.apply(n, root, next)
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// A singular query selects at most one node.
+func (q *jpQuery) singular() bool {
+	for _, segment := range q.segments {
+		if segment.descendant || len(segment.selectors) != 1 {
+			return false
+		}
+		switch segment.selectors[0].(type) {
+		case jpName, jpIndex:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+type jpSegment struct {
+	descendant bool
+	selectors  []jpSelector
+}
+
+func (s jpSegment) apply(n, root *jpNode, out []*jpNode) []*jpNode {
+	for _, selector := range s.selectors {
+		out = selector.selectFrom(n, root, out)
+	}
+	return out
+}
+
+func (s jpSegment) descend(n, root *jpNode, out []*jpNode) []*jpNode {
+	out = s.apply(n, root, out)
+	for _, c := range n.children() {
+		out = s.descend(c, root, out)
+	}
+	return out
+}
+
+type jpSelector interface {
+	selectFrom(n, root *jpNode, out []*jpNode) []*jpNode
+}
+
+type jpName string
+
+func (s jpName) selectFrom(n, root *jpNode, out []*jpNode) []*jpNode {
+	if c, ok := n.child(string(s)); ok {
+		out = append(out, c)
+	}
+	return out
+}
+
+type jpWildcard struct{}
+
+func (jpWildcard) selectFrom(n, root *jpNode, out []*jpNode) []*jpNode {
+	return append(out, n.children()...)
+}
+
+type jpIndex int
+
+func (s jpIndex) selectFrom(n, root *jpNode, out []*jpNode) []*jpNode {
+	if c, ok := n.element(int(s)); ok {
+		out = append(out, c)
+	}
+	return out
+}
+
+type jpSlice struct {
+	start, end, step int
+	hasStart, hasEnd bool
+}
+
+func (s jpSlice) selectFrom(n, root *jpNode, out []*jpNode) []*jpNode {
+	array, ok := n.data.([]interface{})
+	if !ok || s.step == 0 {
+		return out
+	}
+
+	length := len(array)
+	normalize := func(i int) int {
+		if i >= 0 {
+			return i
+		}
+		return length + i
+	}
+	clamp := func(i, lo, hi int) int {
+		if i < lo {
+			return lo
+		}
+		if i > hi {
+			return hi
+		}
+		return i
+	}
+
+	if s.step > 0 {
+		start, end := 0, length
+		if s.hasStart {
+			start = clamp(normalize(s.start), 0, length)
+		}
+		if s.hasEnd {
+			end = clamp(normalize(s.end), 0, length)
+		}
+		for i := start; i < end; i += s.step {
+			out = append(out, &jpNode{data: array[i], parent: n, index: i, isIndex: true})
+		}
+		return out
+	}
+
+	start, end := length-1, -1
+	if s.hasStart {
+		start = clamp(normalize(s.start), -1, length-1)
+	}
+	if s.hasEnd {
+		end = clamp(normalize(s.end), -1, length-1)
+	}
+	for i := start; i > end; i += s.step {
+		out = append(out, &jpNode{data: array[i], parent: n, index: i, isIndex: true})
+	}
+	return out
+}
+
+type jpFilter struct {
+	expr jpLogical
+}
+
+func (s jpFilter) selectFrom(n, root *jpNode, out []*jpNode) []*jpNode {
+	for _, c := range n.children() {
+		if s.expr.test(root, c) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// --- filter expressions ---
+
+type jpLogical interface {
+	test(root, current *jpNode) bool
+}
+
+type jpOr []jpLogical
+
+func (e jpOr) test(root, current *jpNode) bool {
+	for _, term := range e {
+		if term.test(root, current) {
+			return true
+		}
+	}
+	return false
+}
+
+type jpAnd []jpLogical
+
+func (e jpAnd) test(root, current *jpNode) bool {
+	for _, term := range e {
+		if !term.test(root, current) {
+			return false
+		}
+	}
+	return true
+}
+
+type jpNot struct {
+	expr jpLogical
+}
+
+func (e jpNot) test(root, current *jpNode) bool {
+	return !e.expr.test(root, current)
+}
+
+// Existence test of a filter query, e.g. [?@.isbn].
+type jpExists struct {
+	query *jpQuery
+}
+
+func (e jpExists) test(root, current *jpNode) bool {
+	return len(e.query.eval(root, current)) > 0
+}
+
+// Test of a function returning a logical, e.g. [?match(@.a, 'x.*')].
+type jpFuncTest struct {
+	fn *jpFunc
+}
+
+func (e jpFuncTest) test(root, current *jpNode) bool {
+	v, ok := e.fn.eval(root, current)
+	return ok && v == true
+}
+
+type jpComparison struct {
+	op          string
+	left, right jpOperand
+}
+
+func (e jpComparison) test(root, current *jpNode) bool {
+	l, lok := e.left.value(root, current)
+	r, rok := e.right.value(root, current)
+
+	switch e.op {
+	case "==":
+		return jpEqual(l, lok, r, rok)
+	case "!=":
+		return !jpEqual(l, lok, r, rok)
+	case "<":
+		return jpLess(l, lok, r, rok)
+	case ">":
+		return jpLess(r, rok, l, lok)
+	case "<=":
+		return jpLess(l, lok, r, rok) || jpEqual(l, lok, r, rok)
+	case ">=":
+		return jpLess(r, rok, l, lok) || jpEqual(l, lok, r, rok)
+	}
+	return false
+}
+
+// Compares two comparables. ok is false for Nothing (an empty query result).
+func jpEqual(a interface{}, aok bool, b interface{}, bok bool) bool {
+	if !aok || !bok {
+		return aok == bok
+	}
+	return dataEqual(a, b)
+}
+
+func jpLess(a interface{}, aok bool, b interface{}, bok bool) bool {
+	if !aok || !bok {
+		return false
+	}
+	switch a := a.(type) {
+	case json.Number:
+		if b, ok := b.(json.Number); ok {
+			return compareNumbers(a, b) < 0
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			return a < b
+		}
+	}
+	return false
+}
+
+// Reports whether two decoded JSON values are equal.
+// Numbers are compared by value, so 1, 1.0 and 1e0 are equal.
+func dataEqual(a, b interface{}) bool {
+	switch a := a.(type) {
+	case nil:
+		return b == nil
+	case bool:
+		b, ok := b.(bool)
+		return ok && a == b
+	case string:
+		b, ok := b.(string)
+		return ok && a == b
+	case json.Number:
+		b, ok := b.(json.Number)
+		return ok && compareNumbers(a, b) == 0
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !dataEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for key, av := range a {
+			bv, ok := b[key]
+			if !ok || !dataEqual(av, bv) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Compares two numbers by value.
+// int64 values are compared directly, everything else with 256 bits of precision
+// so large integers that only differ beyond float64 precision aren't equal.
+func compareNumbers(a, b json.Number) int {
+	if x, err := a.Int64(); err == nil {
+		if y, err := b.Int64(); err == nil {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	x, _, errA := big.ParseFloat(string(a), 10, 256, big.ToNearestEven)
+	y, _, errB := big.ParseFloat(string(b), 10, 256, big.ToNearestEven)
+	if errA != nil || errB != nil {
+		return strings.Compare(string(a), string(b))
+	}
+	return x.Cmp(y)
+}
+
+// An operand of a comparison or function argument:
+// a literal, a filter query or a function call.
+type jpOperand struct {
+	literal interface{}
+	isLit   bool
+	query   *jpQuery
+	fn      *jpFunc
+}
+
+// Evaluates to a single value. ok is false for Nothing.
+func (o jpOperand) value(root, current *jpNode) (interface{}, bool) {
+	switch {
+	case o.isLit:
+		return o.literal, true
+	case o.query != nil:
+		nodes := o.query.eval(root, current)
+		if len(nodes) != 1 {
+			return nil, false
+		}
+		return nodes[0].data, true
+	case o.fn != nil:
+		return o.fn.eval(root, current)
+	}
+	return nil, false
+}
+
+type jpType int
+
+const (
+	jpValueType jpType = iota
+	jpLogicalType
+	jpNodesType
+)
+
+var jpFunctions = map[string]struct {
+	params []jpType
+	result jpType
+}{
+	"length": {[]jpType{jpValueType}, jpValueType},
+	"count":  {[]jpType{jpNodesType}, jpValueType},
+	"match":  {[]jpType{jpValueType, jpValueType}, jpLogicalType},
+	"search": {[]jpType{jpValueType, jpValueType}, jpLogicalType},
+	"value":  {[]jpType{jpNodesType}, jpValueType},
+}
+
+type jpFunc struct {
+	name string
+	args []jpOperand
+	re   *regexp.Regexp // precompiled when the pattern of match/search is a literal
+}
+
+func (f *jpFunc) result() jpType {
+	return jpFunctions[f.name].result
+}
+
+func (f *jpFunc) eval(root, current *jpNode) (interface{}, bool) {
+	switch f.name {
+	case "length":
+		v, ok := f.args[0].value(root, current)
+		if !ok {
+			return nil, false
+		}
+		switch v := v.(type) {
+		case string:
+			return json.Number(strconv.Itoa(utf8.RuneCountInString(v))), true
+		case []interface{}:
+			return json.Number(strconv.Itoa(len(v))), true
+		case map[string]interface{}:
+			return json.Number(strconv.Itoa(len(v))), true
+		}
+		return nil, false
+	case "count":
+		return json.Number(strconv.Itoa(len(f.args[0].query.eval(root, current)))), true
+	case "value":
+		nodes := f.args[0].query.eval(root, current)
+		if len(nodes) != 1 {
+			return nil, false
+		}
+		return nodes[0].data, true
+	case "match", "search":
+		v, ok := f.args[0].value(root, current)
+		s, isString := v.(string)
+		if !ok || !isString {
+			return false, true
+		}
+		re := f.re
+		if re == nil {
+			pattern, ok := f.args[1].value(root, current)
+			p, isString := pattern.(string)
+			if !ok || !isString {
+				return false, true
+			}
+			var err error
+			if re, err = compileIRegexp(p, f.name == "match"); err != nil {
+				return false, true
+			}
+		}
+		return re.MatchString(s), true
+	}
+	return nil, false
+}
+
+// Translates an I-Regexp (RFC 9485) into a Go regexp.
+// The only difference that matters is that '.' doesn't match \r either.
+func compileIRegexp(pattern string, anchored bool) (*regexp.Regexp, error) {
+	var b strings.Builder
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			b.WriteByte(c)
+			i++
+			c = pattern[i]
+		case c == '[':
+			inClass = true
+		case c == ']':
+			inClass = false
+		case c == '.' && !inClass:
+			b.WriteString(`[^\n\r]`)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	if anchored {
+		return regexp.Compile(`^(?:` + b.String() + `)$`)
+	}
+	return regexp.Compile(b.String())
+}
+
+// --- parser ---
+
+// The largest integer that can be used as an index (I-JSON).
+const jpMaxInt = 1<<53 - 1
+
+type jpParser struct {
+	expr string
+	pos  int
+}
+
+func (p *jpParser) errorf(format string, args ...interface{}) error {
+	return &JSONPathError{Expr: p.expr, Offset: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *jpParser) peek() byte {
+	if p.pos < len(p.expr) {
+		return p.expr[p.pos]
+	}
+	return 0
+}
+
+func (p *jpParser) consume(s string) bool {
+	if strings.HasPrefix(p.expr[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *jpParser) skipSpace() {
+	for p.pos < len(p.expr) {
+		switch p.expr[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *jpParser) parseSegments() ([]jpSegment, error) {
+	var segments []jpSegment
+	for {
+		start := p.pos
+		p.skipSpace()
+		if c := p.peek(); c != '.' && c != '[' {
+			p.pos = start
+			return segments, nil
+		}
+		segment, err := p.parseSegment()
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, segment)
+	}
+}
+
+func (p *jpParser) parseSegment() (jpSegment, error) {
+	var segment jpSegment
+	if p.consume("..") {
+		segment.descendant = true
+		if p.peek() == '[' {
+			selectors, err := p.parseBracketed()
+			segment.selectors = selectors
+			return segment, err
+		}
+	} else if p.consume(".") {
+		// shorthand follows
+	} else {
+		selectors, err := p.parseBracketed()
+		segment.selectors = selectors
+		return segment, err
+	}
+
+	if p.consume("*") {
+		segment.selectors = []jpSelector{jpWildcard{}}
+		return segment, nil
+	}
+	name := p.parseMemberName()
+	if name == "" {
+		return segment, p.errorf("expected member name or '*'")
+	}
+	segment.selectors = []jpSelector{jpName(name)}
+	return segment, nil
+}
+
+func (p *jpParser) parseMemberName() string {
+	start := p.pos
+	for p.pos < len(p.expr) {
+		r, size := utf8.DecodeRuneInString(p.expr[p.pos:])
+		first := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r >= 0x80
+		if !first && !(p.pos > start && r >= '0' && r <= '9') {
+			break
+		}
+		p.pos += size
+	}
+	return p.expr[start:p.pos]
+}
+
+func (p *jpParser) parseBracketed() ([]jpSelector, error) {
+	if !p.consume("[") {
+		return nil, p.errorf("expected '['")
+	}
+	var selectors []jpSelector
+	for {
+		p.skipSpace()
+		selector, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+		p.skipSpace()
+		if p.consume("]") {
+			return selectors, nil
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected ',' or ']'")
+		}
+	}
+}
+
+func (p *jpParser) parseSelector() (jpSelector, error) {
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		return jpName(s), err
+	case c == '*':
+		p.pos++
+		return jpWildcard{}, nil
+	case c == '?':
+		p.pos++
+		p.skipSpace()
+		expr, err := p.parseLogicalOr()
+		return jpFilter{expr}, err
+	case c == ':' || c == '-' || (c >= '0' && c <= '9'):
+		return p.parseIndexOrSlice()
+	}
+	return nil, p.errorf("invalid selector")
+}
+
+func (p *jpParser) parseIndexOrSlice() (jpSelector, error) {
+	var s jpSlice
+	var err error
+
+	if p.peek() != ':' {
+		if s.start, err = p.parseInt(); err != nil {
+			return nil, err
+		}
+		s.hasStart = true
+		p.skipSpace()
+		if !p.consume(":") {
+			return jpIndex(s.start), nil
+		}
+	} else {
+		p.pos++
+	}
+
+	s.step = 1
+	p.skipSpace()
+	if c := p.peek(); c == '-' || (c >= '0' && c <= '9') {
+		if s.end, err = p.parseInt(); err != nil {
+			return nil, err
+		}
+		s.hasEnd = true
+		p.skipSpace()
+	}
+	if p.consume(":") {
+		p.skipSpace()
+		if c := p.peek(); c == '-' || (c >= '0' && c <= '9') {
+			if s.step, err = p.parseInt(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s, nil
+}
+
+func (p *jpParser) parseInt() (int, error) {
+	start := p.pos
+	p.consume("-")
+	digits := p.pos
+	for p.pos < len(p.expr) && p.expr[p.pos] >= '0' && p.expr[p.pos] <= '9' {
+		p.pos++
+	}
+	s := p.expr[start:p.pos]
+	if p.pos == digits || (p.expr[digits] == '0' && (p.pos-digits > 1 || digits > start)) {
+		p.pos = start
+		return 0, p.errorf("invalid integer")
+	}
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || i > jpMaxInt || i < -jpMaxInt {
+		p.pos = start
+		return 0, p.errorf("integer %s out of range", s)
+	}
+	return int(i), nil
+}
+
+// Parses a single or double quoted string literal.
+func (p *jpParser) parseString() (string, error) {
+	quote := p.peek()
+	p.pos++
+
+	var b strings.Builder
+	for {
+		if p.pos >= len(p.expr) {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.expr[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return b.String(), nil
+		case c < 0x20:
+			return "", p.errorf("control character in string")
+		case c != '\\':
+			b.WriteByte(c)
+			p.pos++
+			continue
+		}
+
+		p.pos++
+		switch e := p.peek(); e {
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case '/', '\\':
+			b.WriteByte(e)
+		case '\'', '"':
+			if e != quote {
+				return "", p.errorf("invalid escape")
+			}
+			b.WriteByte(e)
+		case 'u':
+			r, err := p.parseUnicodeEscape()
+			if err != nil {
+				return "", err
+			}
+			b.WriteRune(r)
+			continue
+		default:
+			return "", p.errorf("invalid escape")
+		}
+		p.pos++
+	}
+}
+
+// Parses the XXXX of a \uXXXX escape, including a trailing low surrogate.
+func (p *jpParser) parseUnicodeEscape() (rune, error) {
+	hex := func() (rune, bool) {
+		if p.pos+5 > len(p.expr) {
+			return 0, false
+		}
+		n, err := strconv.ParseUint(p.expr[p.pos+1:p.pos+5], 16, 16)
+		if err != nil {
+			return 0, false
+		}
+		p.pos += 5
+		return rune(n), true
+	}
+
+	r, ok := hex()
+	if !ok {
+		return 0, p.errorf("invalid unicode escape")
+	}
+	if utf16.IsSurrogate(r) {
+		if r >= 0xdc00 || !p.consume(`\`) || p.peek() != 'u' {
+			return 0, p.errorf("invalid surrogate pair")
+		}
+		low, ok := hex()
+		if !ok || low < 0xdc00 || low > 0xdfff {
+			return 0, p.errorf("invalid surrogate pair")
+		}
+		r = utf16.DecodeRune(r, low)
+	}
+	return r, nil
+}
+
+func (p *jpParser) parseLogicalOr() (jpLogical, error) {
+	var terms jpOr
+	for {
+		term, err := p.parseLogicalAnd()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+		start := p.pos
+		p.skipSpace()
+		if !p.consume("||") {
+			p.pos = start
+			break
+		}
+		p.skipSpace()
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return terms, nil
+}
+
+func (p *jpParser) parseLogicalAnd() (jpLogical, error) {
+	var terms jpAnd
+	for {
+		term, err := p.parseBasic()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+		start := p.pos
+		p.skipSpace()
+		if !p.consume("&&") {
+			p.pos = start
+			break
+		}
+		p.skipSpace()
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return terms, nil
+}
+
+func (p *jpParser) parseBasic() (jpLogical, error) {
+	if p.peek() == '!' && !strings.HasPrefix(p.expr[p.pos:], "!=") {
+		p.pos++
+		p.skipSpace()
+		if p.peek() == '(' {
+			expr, err := p.parseParen()
+			return jpNot{expr}, err
+		}
+		expr, err := p.parseBasic()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := expr.(jpComparison); ok {
+			return nil, p.errorf("comparison must be parenthesized to be negated")
+		}
+		return jpNot{expr}, nil
+	}
+
+	if p.peek() == '(' {
+		return p.parseParen()
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	start := p.pos
+	p.skipSpace()
+	op := ""
+	for _, candidate := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		p.pos = start
+		switch {
+		case left.query != nil:
+			return jpExists{left.query}, nil
+		case left.fn != nil && left.fn.result() != jpValueType:
+			return jpFuncTest{left.fn}, nil
+		}
+		return nil, p.errorf("expected comparison operator")
+	}
+
+	p.skipSpace()
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range []jpOperand{left, right} {
+		if o.query != nil && !o.query.singular() {
+			return nil, p.errorf("non-singular query in comparison")
+		}
+		if o.fn != nil && o.fn.result() != jpValueType {
+			return nil, p.errorf("function %s() can't be compared", o.fn.name)
+		}
+	}
+	return jpComparison{op, left, right}, nil
+}
+
+func (p *jpParser) parseParen() (jpLogical, error) {
+	p.pos++
+	p.skipSpace()
+	expr, err := p.parseLogicalOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if !p.consume(")") {
+		return nil, p.errorf("expected ')'")
+	}
+	return expr, nil
+}
+
+// Parses a literal, a filter query (@... or $...) or a function call.
+func (p *jpParser) parseOperand() (jpOperand, error) {
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		p.pos++
+		segments, err := p.parseSegments()
+		return jpOperand{query: &jpQuery{relative: c == '@', segments: segments}}, err
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		return jpOperand{literal: s, isLit: true}, err
+	case c == '-' || (c >= '0' && c <= '9'):
+		n, err := p.parseNumber()
+		return jpOperand{literal: n, isLit: true}, err
+	case p.consume("true"):
+		return jpOperand{literal: true, isLit: true}, nil
+	case p.consume("false"):
+		return jpOperand{literal: false, isLit: true}, nil
+	case p.consume("null"):
+		return jpOperand{literal: nil, isLit: true}, nil
+	case c >= 'a' && c <= 'z':
+		fn, err := p.parseFunction()
+		return jpOperand{fn: fn}, err
+	}
+	return jpOperand{}, p.errorf("expected literal, query or function")
+}
+
+func (p *jpParser) parseNumber() (json.Number, error) {
+	start := p.pos
+	p.consume("-")
+	digits := p.pos
+	for p.pos < len(p.expr) && p.expr[p.pos] >= '0' && p.expr[p.pos] <= '9' {
+		p.pos++
+	}
+	if p.pos == digits || (p.expr[digits] == '0' && p.pos-digits > 1) {
+		p.pos = start
+		return "", p.errorf("invalid number")
+	}
+	if p.consume(".") {
+		fraction := p.pos
+		for p.pos < len(p.expr) && p.expr[p.pos] >= '0' && p.expr[p.pos] <= '9' {
+			p.pos++
+		}
+		if p.pos == fraction {
+			return "", p.errorf("invalid number")
+		}
+	}
+	if c := p.peek(); c == 'e' || c == 'E' {
+		p.pos++
+		if c := p.peek(); c == '+' || c == '-' {
+			p.pos++
+		}
+		exponent := p.pos
+		for p.pos < len(p.expr) && p.expr[p.pos] >= '0' && p.expr[p.pos] <= '9' {
+			p.pos++
+		}
+		if p.pos == exponent {
+			return "", p.errorf("invalid number")
+		}
+	}
+	return json.Number(p.expr[start:p.pos]), nil
+}
+
+func (p *jpParser) parseFunction() (*jpFunc, error) {
+	start := p.pos
+	for p.pos < len(p.expr) {
+		c := p.expr[p.pos]
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9')) {
+			break
+		}
+		p.pos++
+	}
+	fn := &jpFunc{name: p.expr[start:p.pos]}
+	signature, ok := jpFunctions[fn.name]
+	if !ok {
+		p.pos = start
+		return nil, p.errorf("unknown function %q", fn.name)
+	}
+	if !p.consume("(") {
+		return nil, p.errorf("expected '('")
+	}
+
+	for i := 0; ; i++ {
+		p.skipSpace()
+		if p.consume(")") {
+			break
+		}
+		if i > 0 {
+			if !p.consume(",") {
+				return nil, p.errorf("expected ',' or ')'")
+			}
+			p.skipSpace()
+		}
+		arg, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		fn.args = append(fn.args, arg)
+	}
+
+	if len(fn.args) != len(signature.params) {
+		return nil, p.errorf("%s() takes %d arguments", fn.name, len(signature.params))
+	}
+	for i, param := range signature.params {
+		arg := fn.args[i]
+		switch param {
+		case jpNodesType:
+			if arg.query == nil {
+				return nil, p.errorf("%s() argument %d must be a query", fn.name, i+1)
+			}
+		case jpValueType:
+			if (arg.query != nil && !arg.query.singular()) || (arg.fn != nil && arg.fn.result() != jpValueType) {
+				return nil, p.errorf("%s() argument %d must be a value", fn.name, i+1)
+			}
+		}
+	}
+
+	if fn.name == "match" || fn.name == "search" {
+		if pattern, ok := fn.args[1].literal.(string); ok && fn.args[1].isLit {
+			re, err := compileIRegexp(pattern, fn.name == "match")
+			if err != nil {
+				return nil, p.errorf("invalid regular expression: %v", err)
+			}
+			fn.re = re
+		}
+	}
+	return fn, nil
+}
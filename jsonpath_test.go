@@ -0,0 +1,139 @@
+package jason
+
+import (
+	"strings"
+	"testing"
+)
+
+const storeJSON = `{
+	"store": {
+		"book": [
+			{"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+			{"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+			{"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+			{"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99}
+		],
+		"bicycle": {"color": "red", "price": 399}
+	}
+}`
+
+func TestJSONPathQuery(t *testing.T) {
+	v, err := NewValue(strings.NewReader(storeJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		expr string
+		want string
+	}{
+		{`$.store.book[*].author`, `"Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"`},
+		{`$..author`, `"Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"`},
+		{`$.store..price`, `399,8.95,12.99,8.99,22.99`},
+		{`$..book[2].title`, `"Moby Dick"`},
+		{`$..book[-1].title`, `"The Lord of the Rings"`},
+		{`$..book[0,1].title`, `"Sayings of the Century","Sword of Honour"`},
+		{`$..book[:2].title`, `"Sayings of the Century","Sword of Honour"`},
+		{`$..book[1:4:2].title`, `"Sword of Honour","The Lord of the Rings"`},
+		{`$..book[::-1].price`, `22.99,8.99,12.99,8.95`},
+		{`$..book[?@.isbn].title`, `"Moby Dick","The Lord of the Rings"`},
+		{`$..book[?@.price < 10].title`, `"Sayings of the Century","Moby Dick"`},
+		{`$..book[?@.price > 10 && @.category == 'fiction'].title`, `"Sword of Honour","The Lord of the Rings"`},
+		{`$..book[?!(@.price < 10)].title`, `"Sword of Honour","The Lord of the Rings"`},
+		{`$..book[?@.price == $.store.book[0].price].author`, `"Nigel Rees"`},
+		{`$..book[?match(@.author, 'J.*')].author`, `"J. R. R. Tolkien"`},
+		{`$..book[?search(@.title, "of")].title`, `"Sayings of the Century","Sword of Honour","The Lord of the Rings"`},
+		{`$..book[?length(@.title) == 9].title`, `"Moby Dick"`},
+		{`$.store[?count(@.*) > 2][0].author`, `"Nigel Rees"`},
+		{`$.store[?count(@[*]) == 2].color`, `"red"`},
+		{`$.store.bicycle['color', "price"]`, `"red",399`},
+		{`$.nothing`, ``},
+	}
+	for _, c := range cases {
+		values, err := v.Query(c.expr)
+		if err != nil {
+			t.Errorf("%s: %v", c.expr, err)
+			continue
+		}
+		got := make([]string, len(values))
+		for i, value := range values {
+			b, _ := value.Marshal()
+			got[i] = string(b)
+		}
+		if strings.Join(got, ",") != c.want {
+			t.Errorf("%s: got %s, want %s", c.expr, strings.Join(got, ","), c.want)
+		}
+	}
+}
+
+func TestJSONPathNormalizedPaths(t *testing.T) {
+	v, err := NewValueFromBytes([]byte(`{"a": [{"b'c": 1}, {"d": 2}], "e": {"b'c": 3}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := MustCompileJSONPath(`$..["b'c"]`)
+	values, paths := p.Query(v)
+	want := []string{`$['a'][0]['b\'c']`, `$['e']['b\'c']`}
+	if len(values) != len(want) {
+		t.Fatal(paths)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("got %s, want %s", paths[i], want[i])
+		}
+	}
+
+	// A compiled query can be reused across documents.
+	other, _ := NewValueFromBytes([]byte(`{"b'c": true}`))
+	values, paths = p.Query(other)
+	if len(values) != 1 || paths[0] != `$['b\'c']` {
+		t.Error(paths)
+	}
+}
+
+func TestJSONPathMemberOrder(t *testing.T) {
+	const doc = `{"z": {"b": 1, "a": 2}, "y": [{"d": 3, "c": 4}], "x": 5}`
+	cases := []struct {
+		opts ParseOptions
+		want string
+	}{
+		{ParseOptions{PreserveOrder: true}, "$['z'] $['y'] $['x'] $['z']['b'] $['z']['a'] $['y'][0] $['y'][0]['d'] $['y'][0]['c']"},
+		{ParseOptions{}, "$['x'] $['y'] $['z'] $['y'][0] $['y'][0]['c'] $['y'][0]['d'] $['z']['a'] $['z']['b']"},
+	}
+	for _, c := range cases {
+		v, err := c.opts.NewValueFromBytes([]byte(doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, paths := MustCompileJSONPath("$..*").Query(v)
+		if got := strings.Join(paths, " "); got != c.want {
+			t.Errorf("PreserveOrder %v: got %s, want %s", c.opts.PreserveOrder, got, c.want)
+		}
+	}
+}
+
+func TestJSONPathErrors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`a`,
+		`$.`,
+		`$[`,
+		`$[01]`,
+		`$[-0]`,
+		`$['a`,
+		`$ `,
+		`$[?@.a == @..b]`,
+		`$[?@.a == 1 ||]`,
+		`$[?length(@.*) > 1]`,
+		`$[?unknown(@.a)]`,
+		`$[?!@.a == 1]`,
+		`$[?match(@.a, '(')]`,
+	} {
+		if _, err := CompileJSONPath(expr); err == nil {
+			t.Errorf("%q: expected an error", expr)
+		} else if _, ok := err.(*JSONPathError); !ok {
+			t.Errorf("%q: unexpected error type %T", expr, err)
+		}
+	}
+}
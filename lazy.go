@@ -0,0 +1,477 @@
+package jason
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// The structural index of a lazily parsed document.
+type lazyDoc struct {
+	buf    []byte
+	opens  []int // Offsets of { and [ in document order
+	closes []int // Offsets of the matching } and ]
+	lines  []int // Offsets of newlines
+	cont   []int // Offsets of UTF-8 continuation bytes, which don't start a new column
+}
+
+// A value of a lazily parsed document that hasn't been parsed yet.
+type lazyValue struct {
+	doc        *lazyDoc
+	start, end int
+	keyPos     Position
+	elems      []*lazyValue // The elements of an array once they were scanned, see elements
+	scanned    bool
+}
+
+// Creates a value that is parsed on demand.
+// Only the structure of b is scanned up front; objects, arrays and scalars are parsed when
+// they are read, and Get and the Get<Type> methods skip over everything they don't touch.
+// Results are the same as with NewValueFromBytes, but syntax errors inside values that
+// are never read go unnoticed, and errors in values that are read are returned by the getter.
+// Nesting deeper than DefaultMaxDepth is rejected up front, with the same error as from NewValueFromBytes.
+// b must not be modified while the value is in use.
+// A lazy value must not be read from several goroutines at the same time.
+// Example:
+//
+//	v, err := jason.NewLazyValue(payload)
+//	id, err := v.Get("event").Get("id").String()
+func NewLazyValue(b []byte) (*Value, error) {
+	doc := &lazyDoc{buf: b}
+	start := doc.skipSpace(0)
+	if start == len(b) {
+		return nil, io.EOF
+	}
+	for i, c := range b[:start] {
+		if c == '\n' {
+			doc.lines = append(doc.lines, i)
+		}
+	}
+	end, err := doc.index(start)
+	if err != nil {
+		return nil, err
+	}
+	return &Value{lazy: &lazyValue{doc: doc, start: start, end: end}}, nil
+}
+
+// Creates an object that is parsed on demand. See NewLazyValue.
+func NewLazyObject(b []byte) (*Object, error) {
+	return objectFromValue(NewLazyValue(b))
+}
+
+func (d *lazyDoc) skipSpace(i int) int {
+	for ; i < len(d.buf); i++ {
+		switch d.buf[i] {
+		case ' ', '\t', '\r', '\n':
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// Scans the value starting at start, matching brackets and recording newlines.
+// Returns the end of the value.
+func (d *lazyDoc) index(start int) (int, error) {
+	b := d.buf
+	if b[start] != '{' && b[start] != '[' {
+		if end := d.scalarEnd(start); end > start {
+			return end, nil
+		}
+		return 0, d.syntaxError(start, "looking for beginning of value")
+	}
+
+	var stack []int
+	for i := start; i < len(b); i++ {
+		switch b[i] {
+		case '"':
+			for i++; i < len(b) && b[i] != '"'; i++ {
+				if b[i] == '\\' {
+					i++
+				} else if b[i] == '\n' {
+					d.lines = append(d.lines, i)
+				} else if b[i]&0xC0 == 0x80 {
+					d.cont = append(d.cont, i)
+				}
+			}
+		case '\n':
+			d.lines = append(d.lines, i)
+		case '{', '[':
+			if len(stack) == DefaultMaxDepth {
+				return 0, d.depthError()
+			}
+			stack = append(stack, len(d.opens))
+			d.opens = append(d.opens, i)
+			d.closes = append(d.closes, -1)
+		case '}', ']':
+			top := len(stack) - 1
+			if top < 0 || b[d.opens[stack[top]]] != b[i]-2 {
+				// } and ] are two bytes after { and [
+				return 0, d.syntaxError(i, "mismatched bracket")
+			}
+			d.closes[stack[top]] = i
+			stack = stack[:top]
+			if len(stack) == 0 {
+				return i + 1, nil
+			}
+		}
+	}
+	return 0, &SyntaxError{Msg: "unexpected end of JSON input", Position: d.position(len(b))}
+}
+
+// Reports nesting deeper than DefaultMaxDepth. The parser finds it too, and its error has the path.
+func (d *lazyDoc) depthError() error {
+	if _, _, err := newBytesParser(d.buf).parse(); err != nil {
+		return err
+	}
+	return &LimitError{Limit: "MaxDepth", Max: DefaultMaxDepth}
+}
+
+// Returns the end of the scalar starting at i.
+func (d *lazyDoc) scalarEnd(i int) int {
+	b := d.buf
+	if b[i] == '"' {
+		for i++; i < len(b) && b[i] != '"'; i++ {
+			if b[i] == '\\' {
+				i++
+			}
+		}
+		if i < len(b) {
+			i++
+		}
+		if i > len(b) {
+			i = len(b)
+		}
+		return i
+	}
+	for ; i < len(b); i++ {
+		switch b[i] {
+		case ' ', '\t', '\r', '\n', ',', ':', ']', '}', '[', '{', '"':
+			return i
+		}
+	}
+	return i
+}
+
+// Returns the end of the value starting at i.
+func (d *lazyDoc) valueEnd(i int) int {
+	if c := d.buf[i]; c == '{' || c == '[' {
+		k := sort.SearchInts(d.opens, i)
+		return d.closes[k] + 1
+	}
+	return d.scalarEnd(i)
+}
+
+// Returns the position of the byte at offset, counted the same way as the parser does.
+func (d *lazyDoc) position(offset int) Position {
+	line := sort.SearchInts(d.lines, offset)
+	lineStart := 0
+	if line > 0 {
+		lineStart = d.lines[line-1] + 1
+	}
+	column := offset - lineStart + 1 - (sort.SearchInts(d.cont, offset) - sort.SearchInts(d.cont, lineStart))
+	return Position{Offset: offset, Line: line + 1, Column: column}
+}
+
+func (d *lazyDoc) syntaxError(i int, msg string) error {
+	if i >= len(d.buf) {
+		return &SyntaxError{Msg: "unexpected end of JSON input", Position: d.position(len(d.buf))}
+	}
+	return &SyntaxError{Msg: fmt.Sprintf("invalid character %q %s", d.buf[i], msg), Position: d.position(i)}
+}
+
+func (l *lazyValue) kind() Kind {
+	switch c := l.doc.buf[l.start]; {
+	case c == '{':
+		return KindObject
+	case c == '[':
+		return KindArray
+	case c == '"':
+		return KindString
+	case c == 't' || c == 'f':
+		return KindBool
+	case c == 'n':
+		return KindNull
+	}
+	return KindNumber
+}
+
+// Calls fn with the members of an object or the elements of an array in document order,
+// until fn returns false. key is the raw member name including quotes, or nil for arrays.
+func (l *lazyValue) each(fn func(key []byte, keyStart, start, end int) bool) error {
+	d := l.doc
+	b := d.buf
+	isObject := b[l.start] == '{'
+	closing := b[l.end-1]
+
+	i := d.skipSpace(l.start + 1)
+	if i < l.end && b[i] == closing {
+		return nil
+	}
+	for {
+		var key []byte
+		keyStart := i
+		if isObject {
+			if i >= l.end || b[i] != '"' {
+				return d.syntaxError(i, "looking for beginning of object key string")
+			}
+			keyEnd := d.scalarEnd(i)
+			key = b[i:keyEnd]
+			if i = d.skipSpace(keyEnd); i >= l.end || b[i] != ':' {
+				return d.syntaxError(i, "after object key")
+			}
+			i = d.skipSpace(i + 1)
+		}
+		if i >= l.end-1 {
+			return d.syntaxError(i, "looking for beginning of value")
+		}
+		end := d.valueEnd(i)
+		if end == i {
+			return d.syntaxError(i, "looking for beginning of value")
+		}
+		if !fn(key, keyStart, i, end) {
+			return nil
+		}
+
+		i = d.skipSpace(end)
+		if i < l.end && b[i] == ',' {
+			i = d.skipSpace(i + 1)
+			continue
+		}
+		if i < l.end && b[i] == closing {
+			return nil
+		}
+		if isObject {
+			return d.syntaxError(i, "after object key:value pair")
+		}
+		return d.syntaxError(i, "after array element")
+	}
+}
+
+// Finds the member with the given name, scanning the whole object so a repeated name yields its last value.
+func (l *lazyValue) member(name string) (*lazyValue, error) {
+	var found *lazyValue
+	err := l.each(func(key []byte, keyStart, start, end int) bool {
+		if lazyKeyEqual(key, name) {
+			found = &lazyValue{doc: l.doc, start: start, end: end, keyPos: l.doc.position(keyStart)}
+		}
+		return true
+	})
+	return found, err
+}
+
+func lazyKeyEqual(key []byte, name string) bool {
+	raw := key[1 : len(key)-1]
+	if bytes.IndexByte(raw, '\\') < 0 && utf8.Valid(raw) {
+		return string(raw) == name
+	}
+	s, err := newBytesParser(key).string()
+	return err == nil && s == name
+}
+
+// Returns the elements of an array. They are scanned once, later calls return the same ones.
+func (l *lazyValue) elements() ([]*lazyValue, error) {
+	if l.scanned {
+		return l.elems, nil
+	}
+	var elements []*lazyValue
+	err := l.each(func(_ []byte, _, start, end int) bool {
+		elements = append(elements, &lazyValue{doc: l.doc, start: start, end: end})
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	l.elems, l.scanned = elements, true
+	return elements, nil
+}
+
+// Checks the member names and the structure of an object without parsing the members.
+func (l *lazyValue) checkMembers() error {
+	var keyErr error
+	err := l.each(func(key []byte, keyStart, _, _ int) bool {
+		p := newBytesParser(key)
+		p.pos = l.doc.position(keyStart)
+		_, keyErr = p.string()
+		return keyErr == nil
+	})
+	if err != nil {
+		return err
+	}
+	return keyErr
+}
+
+// Parses the value.
+func (l *lazyValue) parse() (interface{}, *parseNode, error) {
+	// The parser sees the rest of the document, so that an invalid literal like tru} is reported
+	// at the character that is wrong and not as the end of the input
+	p := newBytesParser(l.doc.buf[l.start:])
+	p.pos = l.doc.position(l.start)
+	data, node, err := p.parse()
+	if err == nil {
+		p.buf = p.buf[:l.end-l.start]
+		err = p.end()
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	node.keyPos = l.keyPos
+	return data, node, nil
+}
+
+// Parses a lazily read value on first use.
+func (v *Value) load() error {
+	if v.lazy == nil {
+		return nil
+	}
+	data, node, err := v.lazy.parse()
+	if err != nil {
+		return err
+	}
+	v.data, v.node, v.lazy = data, node, nil
+	return nil
+}
+
+// Creates the value of a member or element of a lazy value without parsing it.
+func (v *Value) lazyChild(key string, l *lazyValue, exists bool) *Value {
+	return &Value{exists: exists, parent: v, key: key, lazy: l}
+}
+
+// Looks up a member name or array index in a lazy value. See Value.get.
+func (v *Value) lazyGetKey(key string) (*Value, error) {
+	switch v.lazy.kind() {
+	case KindObject:
+		child, err := v.lazy.member(key)
+		if err != nil {
+			return nil, err
+		}
+		if child == nil {
+			return nil, v.lookupError(key, KeyNotFoundError{key})
+		}
+		return v.lazyChild(key, child, true), nil
+	case KindArray:
+		elements, err := v.lazy.elements()
+		if err != nil {
+			return nil, err
+		}
+		i, err := arrayIndex(key, len(elements))
+		if err == ErrNotObject {
+			return nil, v.typeError(KindObject, err)
+		} else if err != nil {
+			return nil, v.lookupError(key, err)
+		}
+		return v.lazyChild(strconv.Itoa(i), elements[i], true), nil
+	}
+	return nil, v.typeError(KindObject, ErrNotObject)
+}
+
+// Gets a member or element of a lazy value. See Value.Get.
+func (v *Value) lazyGet(i interface{}) *Value {
+	switch i := i.(type) {
+	case string:
+		if v.lazy.kind() != KindObject {
+			return &Value{Err: v.typeError(KindObject, ErrNotObject)}
+		}
+		child, err := v.lazy.member(i)
+		if err != nil {
+			return &Value{Err: err}
+		}
+		if child == nil {
+			return &Value{Err: v.lookupError(i, KeyNotFoundError{i})}
+		}
+		return v.lazyChild(i, child, child.kind() != KindNull)
+	case int:
+		if v.lazy.kind() != KindArray {
+			return &Value{Err: v.typeError(KindArray, ErrNotArray)}
+		}
+		elements, err := v.lazy.elements()
+		if err != nil {
+			return &Value{Err: err}
+		}
+		index := i
+		if index < 0 {
+			index += len(elements)
+		}
+		if index < 0 || index >= len(elements) {
+			return &Value{Err: v.lookupError(strconv.Itoa(i), ErrIndexOutOfRange)}
+		}
+		return v.lazyChild(strconv.Itoa(index), elements[index], elements[index].kind() != KindNull)
+	}
+	return &Value{Err: &PathError{Path: v.path(), Err: ErrInvalidKey, Position: v.Position()}}
+}
+
+// Returns the elements of a lazy array without parsing them. See Value.Array.
+func (v *Value) lazyArray() ([]*Value, error) {
+	elements, err := v.lazy.elements()
+	if err != nil {
+		return nil, err
+	}
+	var slice []*Value
+	for i, element := range elements {
+		slice = append(slice, v.lazyChild(strconv.Itoa(i), element, true))
+	}
+	return slice, nil
+}
+
+// Returns the elements of a lazy array of objects without parsing them. See Value.ObjectArray.
+func (v *Value) lazyObjectArray() ([]*Object, error) {
+	elements, err := v.lazyArray()
+	if err != nil {
+		return nil, err
+	}
+	var slice []*Object
+	for _, element := range elements {
+		if element.lazy.kind() != KindObject {
+			return nil, element.typeError(KindObject, ErrNotObjectArray)
+		}
+		o, _ := element.Object()
+		slice = append(slice, o)
+	}
+	return slice, nil
+}
+
+// Builds the map of a lazy object without parsing the members. See Object.Map.
+// Returns nil if the object is malformed, which Value.Object has already reported.
+func (v *Object) lazyMap() map[string]*Value {
+	m := make(map[string]*Value)
+	var keyErr error
+	err := v.lazy.each(func(key []byte, keyStart, start, end int) bool {
+		var name interface{}
+		if name, keyErr = newBytesParser(key).string(); keyErr != nil {
+			return false
+		}
+		child := &lazyValue{doc: v.lazy.doc, start: start, end: end, keyPos: v.lazy.doc.position(keyStart)}
+		m[name.(string)] = v.Value.lazyChild(name.(string), child, true)
+		return true
+	})
+	if err != nil || keyErr != nil {
+		return nil
+	}
+	return m
+}
+
+// Returns the member names of a lazy object in document order without parsing the members.
+// See Object.Keys. Returns nil if the object is malformed, which Value.Object has already reported.
+func (v *Object) lazyKeys() []string {
+	var keys []string
+	seen := make(map[string]bool)
+	var keyErr error
+	err := v.lazy.each(func(key []byte, keyStart, start, end int) bool {
+		var name interface{}
+		if name, keyErr = newBytesParser(key).string(); keyErr != nil {
+			return false
+		}
+		if !seen[name.(string)] {
+			seen[name.(string)] = true
+			keys = append(keys, name.(string))
+		}
+		return true
+	})
+	if err != nil || keyErr != nil {
+		return nil
+	}
+	return keys
+}
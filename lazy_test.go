@@ -0,0 +1,201 @@
+package jason
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+const lazyDocument = `{
+  "name": "anton",
+  "age": 29,
+  "score": 1.5,
+  "verified": true,
+  "nothing": null,
+  "escaped": "kéy",
+  "dup": 1, "dup": 2,
+  "tags": ["a", "b", "ü"],
+  "numbers": [1, 2.5, -3e2],
+  "flags": [true, false],
+  "nulls": [null, null],
+  "friends": [
+    {"name": "bob", "age": 30, "address": {"city": "Stockholm"}},
+    {"name": "carl", "age": 31}
+  ],
+  "mixed": [{"a": 1}, 2],
+  "empty": {},
+  "emptyArray": []
+}`
+
+func TestLazyMatchesEager(t *testing.T) {
+	eager, err := NewObjectFromBytes([]byte(lazyDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lazy, err := NewLazyObject([]byte(lazyDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	getters := map[string]func(o *Object, keys ...string) string{
+		"value": func(o *Object, keys ...string) string {
+			v, err := o.GetValue(keys...)
+			if err != nil {
+				return err.Error()
+			}
+			b, err := v.Marshal()
+			return fmt.Sprint(string(b), err, v.Position(), v.KeyPosition())
+		},
+		"object": func(o *Object, keys ...string) string {
+			v, err := o.GetObject(keys...)
+			if err != nil {
+				return err.Error()
+			}
+			return fmt.Sprint(v, len(v.Map()))
+		},
+		"string": func(o *Object, keys ...string) string { return fmt.Sprint(o.GetString(keys...)) },
+		"null":   func(o *Object, keys ...string) string { return fmt.Sprint(o.GetNull(keys...)) },
+		"number": func(o *Object, keys ...string) string { return fmt.Sprint(o.GetNumber(keys...)) },
+		"int64":  func(o *Object, keys ...string) string { return fmt.Sprint(o.GetInt64(keys...)) },
+		"bool":   func(o *Object, keys ...string) string { return fmt.Sprint(o.GetBoolean(keys...)) },
+		"strings": func(o *Object, keys ...string) string {
+			return fmt.Sprint(o.GetStringArray(keys...))
+		},
+		"floats": func(o *Object, keys ...string) string { return fmt.Sprint(o.GetFloat64Array(keys...)) },
+		"nulls":  func(o *Object, keys ...string) string { return fmt.Sprint(o.GetNullArray(keys...)) },
+		"objects": func(o *Object, keys ...string) string {
+			objects, err := o.GetObjectArray(keys...)
+			var names []string
+			for _, o := range objects {
+				name, _ := o.GetString("name")
+				names = append(names, name)
+			}
+			return fmt.Sprint(names, err)
+		},
+		"interface": func(o *Object, keys ...string) string { return fmt.Sprint(o.GetInterface(keys...)) },
+	}
+	paths := [][]string{
+		{}, {"name"}, {"age"}, {"score"}, {"verified"}, {"nothing"}, {"escaped"}, {"dup"},
+		{"tags"}, {"tags", "2"}, {"tags", "-1"}, {"tags", "3"}, {"tags", "x"},
+		{"numbers"}, {"flags"}, {"nulls"}, {"friends"}, {"friends", "0", "address", "city"},
+		{"friends", "1", "address"}, {"mixed"}, {"empty"}, {"emptyArray"}, {"missing"}, {"name", "x"},
+	}
+	for name, get := range getters {
+		for _, path := range paths {
+			if want, got := get(eager, path...), get(lazy, path...); got != want {
+				t.Errorf("%s %v: got %s, want %s", name, path, got, want)
+			}
+		}
+	}
+
+	ev, _ := NewValueFromBytes([]byte(lazyDocument))
+	lv, _ := NewLazyValue([]byte(lazyDocument))
+	chains := []func(v *Value) *Value{
+		func(v *Value) *Value { return v.Get("friends").Get(-1).Get("name") },
+		func(v *Value) *Value { return v.Get("nothing") },
+		func(v *Value) *Value { return v.Get("nulls").Get(0) },
+		func(v *Value) *Value { return v.Get("tags").Get(5) },
+		func(v *Value) *Value { return v.Get("tags").Get("a") },
+		func(v *Value) *Value { return v.Get("name").Get(0) },
+		func(v *Value) *Value { return v.Get(1.5) },
+	}
+	for i, chain := range chains {
+		e, l := chain(ev), chain(lv)
+		want := fmt.Sprint(e.Interface(), e.Err, e.Null(), e.Kind(), e.Position())
+		if got := fmt.Sprint(l.Interface(), l.Err, l.Null(), l.Kind(), l.Position()); got != want {
+			t.Errorf("chain %d: got %s, want %s", i, got, want)
+		}
+	}
+
+	if b, _ := lazy.MarshalJSON(); string(b) != eager.String() {
+		t.Errorf("got %s", b)
+	}
+	all, err := lv.GetAll()
+	if err != nil || len(all) != 15 {
+		t.Error(len(all), err)
+	}
+}
+
+func TestLazyLeavesUntouchedValuesUnparsed(t *testing.T) {
+	v, err := NewLazyValue([]byte(`{"broken": [1, 2 3], "ok": {"name": "x"}, "list": [{"id": 1}, {"id": tru}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name, err := v.Get("ok").Get("name").String(); err != nil || name != "x" {
+		t.Error(name, err)
+	}
+	if id, err := v.Get("list").Get(0).Get("id").Int64(); err != nil || id != 1 {
+		t.Error(id, err)
+	}
+
+	var e *SyntaxError
+	if _, err := v.Get("broken").Get(1).Int64(); !errors.As(err, &e) {
+		t.Error(err)
+	}
+	if _, err := v.Get("list").Get(1).Get("id").Boolean(); !errors.As(err, &e) || e.Position.Column != 73 {
+		t.Error(err)
+	}
+	if _, err := v.Marshal(); !errors.As(err, &e) {
+		t.Error(err)
+	}
+}
+
+func TestLazySyntaxErrors(t *testing.T) {
+	for _, doc := range []string{`{"a": [1}`, `{"a": "}`, `[1, 2`, `]`} {
+		var e *SyntaxError
+		if _, err := NewLazyValue([]byte(doc)); !errors.As(err, &e) {
+			t.Errorf("%s: %v", doc, err)
+		}
+	}
+	if _, err := NewLazyObject([]byte(`[1]`)); !errors.Is(err, ErrNotObject) {
+		t.Error(err)
+	}
+	if _, err := NewLazyValue([]byte(" \n ")); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestLazyReportsErrorsLikeEager(t *testing.T) {
+	for _, doc := range []string{`{"a": tru}`, `{"a": [1, nul]}`, `{"a": "x", "b": 1x}`} {
+		_, want := NewValueFromBytes([]byte(doc))
+		if want == nil {
+			t.Fatalf("%s: expected an error", doc)
+		}
+		v, err := NewLazyValue([]byte(doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got error
+		for _, key := range []string{"a", "b"} {
+			if _, got = v.Get(key).Marshal(); got != nil {
+				break
+			}
+		}
+		var e *SyntaxError
+		if !errors.As(got, &e) || e.Position != want.(*SyntaxError).Position {
+			t.Errorf("%s: got %v, want %v", doc, got, want)
+		}
+	}
+
+	for _, doc := range []string{`{"a" 1}`, `{"a\x": 1}`, `{"a": 1 "b": 2}`} {
+		var e *SyntaxError
+		if o, err := NewLazyObject([]byte(doc)); !errors.As(err, &e) {
+			t.Errorf("%s: got %v, %v", doc, o, err)
+		}
+	}
+}
+
+func TestLazyArrayScannedOnce(t *testing.T) {
+	v, _ := NewLazyValue([]byte(lazyDocument))
+	numbers := v.Get("numbers")
+	if n, err := numbers.Get(2).Float64(); err != nil || n != -300 {
+		t.Fatal(n, err)
+	}
+	elements := numbers.lazy.elems
+	if len(elements) != 3 {
+		t.Fatalf("got %d elements", len(elements))
+	}
+	if numbers.Get(0).lazy != elements[0] || numbers.Get(-1).lazy != elements[2] {
+		t.Error("elements were scanned again")
+	}
+}
@@ -0,0 +1,84 @@
+package jason
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultMaxDepth is the nesting depth allowed unless Limits.MaxDepth says otherwise.
+// It is the limit of encoding/json and keeps deeply nested input from exhausting the stack.
+const DefaultMaxDepth = 10000
+
+// Limits restricts the size of the input, e.g. of requests from untrusted clients.
+// Zero means no limit, except for MaxDepth.
+// Example:
+//
+//	v, err := jason.ParseOptions{Limits: jason.Limits{MaxDepth: 32, MaxBytes: 1 << 20}}.NewValue(req.Body)
+type Limits struct {
+	MaxDepth         int // Nesting of objects and arrays. DefaultMaxDepth if zero, no limit if negative
+	MaxBytes         int // Bytes read from the input
+	MaxStringLength  int // Bytes of a string or member name after unescaping
+	MaxNumberLength  int // Characters of a number literal
+	MaxObjectKeys    int // Members of an object
+	MaxArrayElements int // Elements of an array
+}
+
+// LimitError is returned when the input exceeds one of the Limits.
+type LimitError struct {
+	Limit    string // Name of the exceeded limit, e.g. "MaxDepth"
+	Max      int
+	Path     string // JSON Pointer of the value that exceeds the limit
+	Position Position
+
+	tokens []string // The reference tokens of Path, innermost first, collected while the parser unwinds
+}
+
+// The message shows the first segments of the path only, for MaxDepth it is as long as the nesting is deep.
+func (e *LimitError) Error() string {
+	path := e.Path
+	if i := nthIndex(path, '/', shownPathSegments); i >= 0 {
+		path = path[:i] + "/…"
+	}
+	return fmt.Sprintf("%s: path %q: exceeds %s of %d", e.Position, path, e.Limit, e.Max)
+}
+
+const shownPathSegments = 8
+
+// Returns the index of the nth occurrence of c in s, counting from 0, or -1.
+func nthIndex(s string, c byte, n int) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == c {
+			if n == 0 {
+				return i
+			}
+			n--
+		}
+	}
+	return -1
+}
+
+// Sets Path from the tokens collected by errorIn.
+func (e *LimitError) setPath() {
+	var b strings.Builder
+	for i := len(e.tokens) - 1; i >= 0; i-- {
+		b.WriteByte('/')
+		b.WriteString(escapePointerToken(e.tokens[i]))
+	}
+	e.Path, e.tokens = b.String(), nil
+}
+
+// Applies the limits. The input of a bytes parser is cut off at MaxBytes.
+func (p *parser) setLimits(l Limits) {
+	if l.MaxDepth == 0 {
+		l.MaxDepth = DefaultMaxDepth
+	}
+	p.limits = l
+	if l.MaxBytes > 0 && p.r == nil && len(p.buf) > l.MaxBytes {
+		p.buf = p.buf[:l.MaxBytes]
+		p.err = &LimitError{Limit: "MaxBytes", Max: l.MaxBytes}
+	}
+}
+
+func (p *parser) limitError(limit string, max int, pos Position) error {
+	return &LimitError{Limit: limit, Max: max, Position: pos}
+}
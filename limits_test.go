@@ -0,0 +1,103 @@
+package jason
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	cases := []struct {
+		limits Limits
+		doc    string
+		limit  string
+		path   string
+		column int
+	}{
+		{Limits{MaxDepth: 2}, `{"a": [1, 2]}`, "", "", 0},
+		{Limits{MaxDepth: 2}, `{"a": [1, {"b": 2}]}`, "MaxDepth", "/a/1", 11},
+		{Limits{MaxDepth: -1}, strings.Repeat("[", DefaultMaxDepth+1) + strings.Repeat("]", DefaultMaxDepth+1), "", "", 0},
+		{Limits{MaxBytes: 13}, `{"a": [1, 2]}`, "", "", 0},
+		{Limits{MaxBytes: 12}, `{"a": [1, 2]}`, "MaxBytes", "", 13},
+		{Limits{MaxStringLength: 3}, `["abc", "abc"]`, "", "", 0},
+		{Limits{MaxStringLength: 3}, `["abc", "abcd"]`, "MaxStringLength", "/1", 9},
+		{Limits{MaxStringLength: 3}, `["abc", "a\nbc"]`, "MaxStringLength", "/1", 9},
+		{Limits{MaxStringLength: 3}, `{"abcd": 1}`, "MaxStringLength", "", 2},
+		{Limits{MaxNumberLength: 4}, `[-1.5, 1e10]`, "", "", 0},
+		{Limits{MaxNumberLength: 4}, `{"n": [123456]}`, "MaxNumberLength", "/n/0", 8},
+		{Limits{MaxObjectKeys: 2}, `{"a": 1, "b": {"c": 1}}`, "", "", 0},
+		{Limits{MaxObjectKeys: 2}, `[{"a": 1, "b": 2, "c": 3}]`, "MaxObjectKeys", "/0", 19},
+		{Limits{MaxArrayElements: 2}, `[[1, 2], []]`, "", "", 0},
+		{Limits{MaxArrayElements: 2}, `{"a~b": [1, 2, 3]}`, "MaxArrayElements", "/a~0b", 16},
+	}
+	for _, c := range cases {
+		for _, reader := range []bool{false, true} {
+			var err error
+			if reader {
+				_, err = ParseOptions{Limits: c.limits}.NewValue(strings.NewReader(c.doc))
+			} else {
+				_, err = ParseOptions{Limits: c.limits}.NewValueFromBytes([]byte(c.doc))
+			}
+			var e *LimitError
+			if c.limit == "" {
+				if err != nil {
+					t.Errorf("%.40s: %v", c.doc, err)
+				}
+			} else if !errors.As(err, &e) {
+				t.Errorf("%.40s: expected a LimitError, got %v", c.doc, err)
+			} else if e.Limit != c.limit || e.Path != c.path || e.Position.Column != c.column {
+				t.Errorf("%.40s: got %v", c.doc, e)
+			}
+		}
+	}
+}
+
+func TestDefaultMaxDepth(t *testing.T) {
+	deep := strings.Repeat("[", DefaultMaxDepth) + strings.Repeat("]", DefaultMaxDepth)
+	if _, err := NewValueFromBytes([]byte(deep)); err != nil {
+		t.Error(err)
+	}
+
+	hostile := strings.Repeat(`{"a":[`, 500000)
+	var e *LimitError
+	if _, err := NewValue(strings.NewReader(hostile)); !errors.As(err, &e) || e.Limit != "MaxDepth" || e.Max != DefaultMaxDepth {
+		t.Error(err)
+	}
+	if !strings.HasPrefix(e.Path, "/a/0/a/0/") || e.Position.Column != 3*DefaultMaxDepth+1 {
+		t.Error(e.Path[:20], e.Position)
+	}
+
+	// Lazy parsing applies the limit too
+	for _, doc := range []string{
+		strings.Repeat(`{"a":[`, DefaultMaxDepth) + strings.Repeat("]}", DefaultMaxDepth),
+		strings.Repeat("[", 2*DefaultMaxDepth) + strings.Repeat("]", 2*DefaultMaxDepth),
+		`{"ok": 1, "deep": ` + strings.Repeat("[", DefaultMaxDepth+1) + strings.Repeat("]", DefaultMaxDepth+1) + "}",
+		strings.Repeat("[", DefaultMaxDepth) + strings.Repeat("]", DefaultMaxDepth),
+	} {
+		_, want := NewValueFromBytes([]byte(doc))
+		_, got := NewLazyValue([]byte(doc))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%.20s: lazy error %.100v, want %.100v", doc, got, want)
+		}
+	}
+}
+
+func TestLimitErrorMessage(t *testing.T) {
+	_, err := ParseOptions{Limits: Limits{MaxArrayElements: 1}}.NewObjectFromBytes([]byte(`{"a": [1, 2]}`))
+	if err == nil || err.Error() != `line 1, column 11: path "/a": exceeds MaxArrayElements of 1` {
+		t.Error(err)
+	}
+
+	// Only the start of long paths is shown
+	_, err = NewValueFromBytes([]byte(strings.Repeat(`{"a":[`, DefaultMaxDepth)))
+	want := fmt.Sprintf(`line 1, column %d: path "/a/0/a/0/a/0/a/0/…": exceeds MaxDepth of %d`, 3*DefaultMaxDepth+1, DefaultMaxDepth)
+	if err == nil || err.Error() != want {
+		t.Errorf("%.200v", err)
+	}
+	_, err = ParseOptions{Limits: Limits{MaxDepth: 8}}.NewValueFromBytes([]byte(`[[[[[[[[[1]]]]]]]]]`))
+	if err == nil || err.Error() != `line 1, column 9: path "/0/0/0/0/0/0/0/0": exceeds MaxDepth of 8` {
+		t.Error(err)
+	}
+}
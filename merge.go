@@ -0,0 +1,194 @@
+package jason
+
+import (
+	"errors"
+	"strconv"
+)
+
+// ErrNoMergeKey is returned by DeepMerge for MergeArrays(ArrayMergeByKey) without MergeByKey.
+var ErrNoMergeKey = errors.New("no merge key, see MergeByKey")
+
+// Applies a JSON Merge Patch (RFC 7396) to target and returns the result.
+// Members of patch replace those of target, objects are merged recursively
+// and null removes a member. Neither target nor patch is modified.
+// Example:
+//
+//	merged := jason.MergePatch(defaults, overrides)
+func MergePatch(target, patch *Value) *Value {
+	return &Value{data: mergePatchData(copyData(target.raw()), patch.raw()), exists: true}
+}
+
+func mergePatchData(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return copyData(patch)
+	}
+
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+	for key, value := range p {
+		if value == nil {
+			delete(t, key)
+		} else {
+			t[key] = mergePatchData(t[key], value)
+		}
+	}
+	return t
+}
+
+// ArrayStrategy selects how DeepMerge combines two arrays.
+type ArrayStrategy int
+
+const (
+	// The array from src replaces the one in dst.
+	ArrayReplace ArrayStrategy = iota
+	// The elements from src are appended to the array in dst.
+	ArrayAppend
+	// Objects with the same value for the merge key are merged, other elements are appended.
+	// The key is set with MergeByKey, DeepMerge returns ErrNoMergeKey without it.
+	ArrayMergeByKey
+)
+
+// ConflictFunc resolves a DeepMerge conflict: both documents have different values at path
+// that can't be merged. The returned value is used in the result; an error aborts the merge.
+type ConflictFunc func(path string, dst, src *Value) (*Value, error)
+
+// MergeOption configures DeepMerge.
+type MergeOption func(*mergeOptions)
+
+type mergeOptions struct {
+	arrays     ArrayStrategy
+	key        string
+	keySet     bool
+	onConflict ConflictFunc
+}
+
+// Sets how arrays are combined. The default is ArrayReplace.
+func MergeArrays(strategy ArrayStrategy) MergeOption {
+	return func(o *mergeOptions) {
+		o.arrays = strategy
+	}
+}
+
+// Merges arrays of objects by the value of key. Implies ArrayMergeByKey.
+func MergeByKey(key string) MergeOption {
+	return func(o *mergeOptions) {
+		o.arrays = ArrayMergeByKey
+		o.key, o.keySet = key, true
+	}
+}
+
+// Sets a function that resolves conflicts. By default the value from src wins.
+func OnMergeConflict(fn ConflictFunc) MergeOption {
+	return func(o *mergeOptions) {
+		o.onConflict = fn
+	}
+}
+
+// Merges src into dst and returns the result. Neither dst nor src is modified.
+// Objects are merged recursively, arrays according to MergeArrays and
+// any other differing values are conflicts, which src wins unless OnMergeConflict is given.
+// Unlike MergePatch, null is an ordinary value.
+// Example:
+//
+//	config, err := jason.DeepMerge(defaults, local, jason.MergeByKey("name"))
+func DeepMerge(dst, src *Value, opts ...MergeOption) (*Value, error) {
+	var o mergeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.arrays == ArrayMergeByKey && !o.keySet {
+		return nil, ErrNoMergeKey
+	}
+
+	data, err := o.merge(nil, dst.raw(), src.raw())
+	if err != nil {
+		return nil, err
+	}
+	return &Value{data: data, exists: true}, nil
+}
+
+func (o *mergeOptions) merge(tokens []string, dst, src interface{}) (interface{}, error) {
+	switch d := dst.(type) {
+	case map[string]interface{}:
+		if s, ok := src.(map[string]interface{}); ok {
+			return o.mergeObjects(tokens, d, s)
+		}
+	case []interface{}:
+		if s, ok := src.([]interface{}); ok && o.arrays != ArrayReplace {
+			return o.mergeArrays(tokens, d, s)
+		}
+	}
+
+	if dataEqual(dst, src) || o.onConflict == nil {
+		return copyData(src), nil
+	}
+	resolved, err := o.onConflict(formatPointer(tokens), &Value{data: dst, exists: true}, &Value{data: src, exists: true})
+	if err != nil {
+		return nil, err
+	}
+	return toData(resolved)
+}
+
+func (o *mergeOptions) mergeObjects(tokens []string, dst, src map[string]interface{}) (interface{}, error) {
+	merged := make(map[string]interface{}, len(dst)+len(src))
+	for key, value := range dst {
+		merged[key] = copyData(value)
+	}
+	for key, value := range src {
+		existing, ok := dst[key]
+		if !ok {
+			merged[key] = copyData(value)
+			continue
+		}
+		child, err := o.merge(append(tokens[:len(tokens):len(tokens)], key), existing, value)
+		if err != nil {
+			return nil, err
+		}
+		merged[key] = child
+	}
+	return merged, nil
+}
+
+func (o *mergeOptions) mergeArrays(tokens []string, dst, src []interface{}) (interface{}, error) {
+	merged := copyData(dst).([]interface{})
+	if o.arrays == ArrayAppend {
+		return append(merged, copyData(src).([]interface{})...), nil
+	}
+
+	for _, element := range src {
+		i := o.findByKey(merged, element)
+		if i < 0 {
+			merged = append(merged, copyData(element))
+			continue
+		}
+		child, err := o.merge(append(tokens[:len(tokens):len(tokens)], strconv.Itoa(i)), merged[i], element)
+		if err != nil {
+			return nil, err
+		}
+		merged[i] = child
+	}
+	return merged, nil
+}
+
+// Returns the index of the object in array with the same merge key as element, or -1.
+func (o *mergeOptions) findByKey(array []interface{}, element interface{}) int {
+	e, ok := element.(map[string]interface{})
+	if !ok {
+		return -1
+	}
+	key, ok := e[o.key]
+	if !ok {
+		return -1
+	}
+	for i, candidate := range array {
+		if c, ok := candidate.(map[string]interface{}); ok {
+			if k, ok := c[o.key]; ok && dataEqual(k, key) {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,122 @@
+package jason
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	// Examples from RFC 7396 appendix A
+	cases := []struct {
+		target, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, c := range cases {
+		target, _ := NewValueFromBytes([]byte(c.target))
+		patch, _ := NewValueFromBytes([]byte(c.patch))
+		merged := MergePatch(target, patch)
+		if b, _ := merged.Marshal(); string(b) != c.want {
+			t.Errorf("%s + %s: got %s, want %s", c.target, c.patch, b, c.want)
+		}
+	}
+
+	target, _ := NewValueFromBytes([]byte(`{"a":{"b":"c"}}`))
+	patch, _ := NewValueFromBytes([]byte(`{"a":{"b":null}}`))
+	MergePatch(target, patch)
+	if b, _ := target.Marshal(); string(b) != `{"a":{"b":"c"}}` {
+		t.Errorf("target changed: %s", b)
+	}
+}
+
+func TestDeepMerge(t *testing.T) {
+	dst, _ := NewValueFromBytes([]byte(`{
+		"name": "app",
+		"debug": false,
+		"tags": ["a"],
+		"servers": [{"name": "web", "port": 80}, {"name": "db", "port": 5432}],
+		"nested": {"keep": 1, "override": 1}
+	}`))
+	src, _ := NewValueFromBytes([]byte(`{
+		"debug": null,
+		"tags": ["b"],
+		"servers": [{"name": "db", "port": 5433}, {"name": "cache", "port": 6379}, "extra"],
+		"nested": {"override": 2}
+	}`))
+
+	cases := []struct {
+		opts []MergeOption
+		want string
+	}{
+		{nil, `{"debug":null,"name":"app","nested":{"keep":1,"override":2},` +
+			`"servers":[{"name":"db","port":5433},{"name":"cache","port":6379},"extra"],"tags":["b"]}`},
+		{[]MergeOption{MergeArrays(ArrayAppend)}, `{"debug":null,"name":"app","nested":{"keep":1,"override":2},` +
+			`"servers":[{"name":"web","port":80},{"name":"db","port":5432},{"name":"db","port":5433},{"name":"cache","port":6379},"extra"],"tags":["a","b"]}`},
+		{[]MergeOption{MergeByKey("name")}, `{"debug":null,"name":"app","nested":{"keep":1,"override":2},` +
+			`"servers":[{"name":"web","port":80},{"name":"db","port":5433},{"name":"cache","port":6379},"extra"],"tags":["a","b"]}`},
+	}
+	for _, c := range cases {
+		merged, err := DeepMerge(dst, src, c.opts...)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if b, _ := merged.Marshal(); string(b) != c.want {
+			t.Errorf("got %s, want %s", b, c.want)
+		}
+	}
+
+	if _, err := DeepMerge(dst, src, MergeArrays(ArrayMergeByKey)); !errors.Is(err, ErrNoMergeKey) {
+		t.Errorf("merge by key without a key: %v", err)
+	}
+	if _, err := DeepMerge(dst, src, MergeByKey("name"), MergeArrays(ArrayAppend)); err != nil {
+		t.Error(err)
+	}
+
+	if b, _ := dst.Marshal(); string(b) != `{"debug":false,"name":"app","nested":{"keep":1,"override":1},`+
+		`"servers":[{"name":"web","port":80},{"name":"db","port":5432}],"tags":["a"]}` {
+		t.Errorf("dst changed: %s", b)
+	}
+}
+
+func TestDeepMergeConflicts(t *testing.T) {
+	dst, _ := NewValueFromBytes([]byte(`{"a": 1, "b": {"c": "x"}, "d": [1], "same": 1}`))
+	src, _ := NewValueFromBytes([]byte(`{"a": 2, "b": {"c": "y"}, "d": [2], "same": 1.0}`))
+
+	var paths []string
+	merged, err := DeepMerge(dst, src, OnMergeConflict(func(path string, d, s *Value) (*Value, error) {
+		paths = append(paths, path)
+		return d, nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := merged.Marshal(); string(b) != `{"a":1,"b":{"c":"x"},"d":[1],"same":1.0}` {
+		t.Errorf("got %s", b)
+	}
+	if len(paths) != 3 {
+		t.Error(paths)
+	}
+
+	errConflict := errors.New("conflict")
+	_, err = DeepMerge(dst, src, OnMergeConflict(func(path string, d, s *Value) (*Value, error) {
+		return nil, errConflict
+	}))
+	if err != errConflict {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,456 @@
+package jason
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// Sets the value at the JSON Pointer path, replacing any existing value.
+// Missing intermediate objects and arrays are created; a missing parent becomes an array
+// when the next reference token is an index or "-", and an object otherwise.
+// An index equal to the array length, or "-", appends to the array.
+// The value may be a *Value, *Object, Go primitive, map, slice or anything encoding/json can marshal.
+// Values read with Get and the other accessors are views of the document: Set, Delete, Insert
+// and Append on them change the document they were read from too.
+// Example:
+//
+//	err := v.Set("/address/street", "Street 42")
+//	err := v.Set("/friends/-", map[string]interface{}{"name": "dave"})
+func (v *Value) Set(path string, value interface{}) error {
+	data, err := toData(value)
+	if err != nil {
+		return err
+	}
+	return v.modify(path, nil, nodeSet, func(interface{}, bool) (interface{}, error) {
+		return data, nil
+	})
+}
+
+// Deletes the object member or array element at the JSON Pointer path.
+// Later array elements are shifted down.
+// Example:
+//
+//	err := v.Delete("/friends/0")
+func (v *Value) Delete(path string) error {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return &PointerError{Pointer: path, Err: err}
+	}
+	if len(tokens) == 0 {
+		return &PointerError{Pointer: path, Err: ErrInvalidPointer}
+	}
+
+	last := tokens[len(tokens)-1]
+	fail := func(err error) (interface{}, error) {
+		return nil, &PointerError{Pointer: path, Path: path, Err: err}
+	}
+	return v.modifyTokens(path, tokens[:len(tokens)-1], tokens[len(tokens)-1:], nodeDelete, func(parent interface{}, exists bool) (interface{}, error) {
+		if !exists {
+			return fail(KeyNotFoundError{last})
+		}
+		switch parent := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := parent[last]; !ok {
+				return fail(KeyNotFoundError{last})
+			}
+			delete(parent, last)
+			return parent, nil
+		case []interface{}:
+			i, err := pointerIndex(last, len(parent))
+			if err != nil {
+				return fail(err)
+			}
+			s := make([]interface{}, 0, len(parent)-1)
+			s = append(s, parent[:i]...)
+			return append(s, parent[i+1:]...), nil
+		}
+		return fail(ErrNotObject)
+	})
+}
+
+// Inserts a value into the array at the JSON Pointer path, shifting later elements up.
+// index may be anything from 0 to the length of the array.
+// The array is created if it doesn't exist.
+// Example:
+//
+//	err := v.Insert("/friends", 0, friend)
+func (v *Value) Insert(path string, index int, value interface{}) error {
+	data, err := toData(value)
+	if err != nil {
+		return err
+	}
+	return v.modify(path, []string{strconv.Itoa(index)}, nodeInsert, func(array interface{}, exists bool) (interface{}, error) {
+		return insertData(array, exists, index, data)
+	})
+}
+
+// Appends a value to the array at the JSON Pointer path.
+// The array is created if it doesn't exist.
+// Example:
+//
+//	err := v.Append("/tags", "new")
+func (v *Value) Append(path string, value interface{}) error {
+	data, err := toData(value)
+	if err != nil {
+		return err
+	}
+	return v.modify(path, []string{"-"}, nodeInsert, func(array interface{}, exists bool) (interface{}, error) {
+		s, _ := array.([]interface{})
+		return insertData(array, exists, len(s), data)
+	})
+}
+
+// Sets the value at the JSON Pointer path, replacing any existing value.
+// See Value.Set. Setting the root of an object to anything but an object fails with ErrNotObject.
+func (v *Object) Set(path string, value interface{}) error {
+	data, err := toData(value)
+	if err != nil {
+		return err
+	}
+	if _, ok := data.(map[string]interface{}); path == "" && !ok {
+		return ErrNotObject
+	}
+	return v.sync(v.Value.Set(path, data))
+}
+
+// Deletes the member or array element at the JSON Pointer path. See Value.Delete.
+func (v *Object) Delete(path string) error {
+	return v.sync(v.Value.Delete(path))
+}
+
+// Inserts a value into the array at the JSON Pointer path. See Value.Insert.
+func (v *Object) Insert(path string, index int, value interface{}) error {
+	return v.sync(v.Value.Insert(path, index, value))
+}
+
+// Appends a value to the array at the JSON Pointer path. See Value.Append.
+func (v *Object) Append(path string, value interface{}) error {
+	return v.sync(v.Value.Append(path, value))
+}
+
+// Drops the map after a mutation so Map() and MarshalJSON rebuild it with the change.
+func (v *Object) sync(err error) error {
+	if err != nil {
+		return err
+	}
+	if _, ok := v.data.(map[string]interface{}); !ok {
+		return ErrNotObject
+	}
+	v.m.Store((*map[string]*Value)(nil))
+	return nil
+}
+
+func insertData(array interface{}, exists bool, index int, data interface{}) (interface{}, error) {
+	var s []interface{}
+	if exists {
+		var ok bool
+		if s, ok = array.([]interface{}); !ok {
+			return nil, ErrNotArray
+		}
+	}
+	if index < 0 || index > len(s) {
+		return nil, ErrIndexOutOfRange
+	}
+
+	inserted := make([]interface{}, 0, len(s)+1)
+	inserted = append(inserted, s[:index]...)
+	inserted = append(inserted, data)
+	return append(inserted, s[index:]...), nil
+}
+
+func (v *Value) modify(path string, changed []string, change nodeChange, fn func(data interface{}, exists bool) (interface{}, error)) error {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return &PointerError{Pointer: path, Err: err}
+	}
+	return v.modifyTokens(path, tokens, changed, change, fn)
+}
+
+// Applies fn to the data at the tokens and writes the results back up to the root.
+// Nothing is changed if fn or the lookup fails.
+// changed are the tokens below the data at tokens that fn changes in the way given by change.
+// A value read from another one writes its new data through to it, so the change is part of
+// the document either way, even where fn replaced an array instead of changing it in place.
+func (v *Value) modifyTokens(path string, tokens, changed []string, change nodeChange, fn func(data interface{}, exists bool) (interface{}, error)) error {
+	if v.Err != nil {
+		return v.Err
+	}
+
+	m := mutation{pointer: path, tokens: tokens, fn: fn}
+	data, err := m.walk(v.raw(), v.raw() != nil, 0)
+	if err != nil {
+		return v.locate(err)
+	}
+
+	// Positions are kept by the value the others were read from
+	owner, prefix := v, []string(nil)
+	for owner.node == nil && owner.parent != nil {
+		prefix = append([]string{owner.key}, prefix...)
+		owner = owner.parent
+	}
+	owner.node = owner.node.update(append(append(prefix, tokens...), changed...), change)
+
+	if o, ok := v.data.(*Object); ok {
+		o.data = data
+		return o.sync(nil)
+	}
+	v.data = data
+	v.exists = true
+	if v.object != nil {
+		v.object.sync(nil)
+	}
+	if v.parent != nil {
+		v.parent.replaceChild(v.key, data)
+	}
+	return nil
+}
+
+// Replaces the data of a member or element after it was changed through a value read from v,
+// and passes the change on up to the root. A lazy value on the way is parsed to take the change.
+// The maps of the Objects on the way are built again on next use.
+func (v *Value) replaceChild(key string, data interface{}) {
+	for current := v; current != nil; current = current.parent {
+		container := current.raw()
+		switch container := container.(type) {
+		case map[string]interface{}:
+			container[key] = data
+		case []interface{}:
+			if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(container) {
+				container[i] = data
+			}
+		}
+		if o, ok := current.data.(*Object); ok {
+			o.sync(nil)
+		}
+		if current.object != nil {
+			current.object.sync(nil)
+		}
+		key, data = current.key, container
+	}
+}
+
+// Sets the position of a pointer error to that of the value its last token was looked up in.
+func (v *Value) locate(err error) error {
+	e, ok := err.(*PointerError)
+	if !ok || e.Path == "" || e.Position.IsValid() {
+		return err
+	}
+	tokens, _ := parsePointer(e.Path)
+	if at, lookupErr := v.Pointer(formatPointer(tokens[:len(tokens)-1])); lookupErr == nil {
+		e.Position = at.Position()
+	}
+	return err
+}
+
+type mutation struct {
+	pointer string
+	tokens  []string
+	fn      func(data interface{}, exists bool) (interface{}, error)
+}
+
+// Returns the new data for the container at depth.
+func (m mutation) walk(data interface{}, exists bool, depth int) (interface{}, error) {
+	if depth == len(m.tokens) {
+		data, err := m.fn(data, exists)
+		if err != nil {
+			if _, ok := err.(*PointerError); !ok {
+				err = &PointerError{Pointer: m.pointer, Path: formatPointer(m.tokens), Err: err}
+			}
+			return nil, err
+		}
+		return data, nil
+	}
+
+	token := m.tokens[depth]
+	if !exists {
+		if token == "-" || isIndexToken(token) {
+			data = []interface{}{}
+		} else {
+			data = map[string]interface{}{}
+		}
+	}
+
+	switch container := data.(type) {
+	case map[string]interface{}:
+		child, ok := container[token]
+		child, err := m.walk(child, ok, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		container[token] = child
+		return container, nil
+	case []interface{}:
+		if token == "-" || token == strconv.Itoa(len(container)) {
+			child, err := m.walk(nil, false, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			return append(container, child), nil
+		}
+		i, err := pointerIndex(token, len(container))
+		if err != nil {
+			return nil, &PointerError{Pointer: m.pointer, Path: formatPointer(m.tokens[:depth+1]), Err: err}
+		}
+		child, err := m.walk(container[i], true, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		container[i] = child
+		return container, nil
+	}
+	return nil, &PointerError{Pointer: m.pointer, Path: formatPointer(m.tokens[:depth+1]), Err: ErrNotObject}
+}
+
+func isIndexToken(token string) bool {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return false
+	}
+	for _, c := range token {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Converts a Go value into the representation produced by the parser.
+// Containers are copied so the result never shares state with the argument.
+func toData(value interface{}) (interface{}, error) {
+	return convertData(value, 0)
+}
+
+// Converts the value at depth for toData.
+// A map or slice that contains itself runs past DefaultMaxDepth and is reported as a cycle.
+func convertData(value interface{}, depth int) (interface{}, error) {
+	if depth > DefaultMaxDepth {
+		return nil, &json.UnsupportedValueError{Value: reflect.ValueOf(value), Str: fmt.Sprintf("encountered a cycle via %T", value)}
+	}
+	switch value := value.(type) {
+	case nil:
+		return nil, nil
+	case *Value:
+		if value == nil {
+			return nil, nil
+		}
+		return copyData(value.raw()), nil
+	case *Object:
+		if value == nil {
+			return nil, nil
+		}
+		return copyData(value.raw()), nil
+	case bool, string:
+		return value, nil
+	case json.Number:
+		if !isNumber(string(value)) {
+			return nil, fmt.Errorf("invalid number literal %q", value)
+		}
+		return value, nil
+	case int:
+		return json.Number(strconv.FormatInt(int64(value), 10)), nil
+	case int8:
+		return json.Number(strconv.FormatInt(int64(value), 10)), nil
+	case int16:
+		return json.Number(strconv.FormatInt(int64(value), 10)), nil
+	case int32:
+		return json.Number(strconv.FormatInt(int64(value), 10)), nil
+	case int64:
+		return json.Number(strconv.FormatInt(value, 10)), nil
+	case uint:
+		return json.Number(strconv.FormatUint(uint64(value), 10)), nil
+	case uint8:
+		return json.Number(strconv.FormatUint(uint64(value), 10)), nil
+	case uint16:
+		return json.Number(strconv.FormatUint(uint64(value), 10)), nil
+	case uint32:
+		return json.Number(strconv.FormatUint(uint64(value), 10)), nil
+	case uint64:
+		return json.Number(strconv.FormatUint(value, 10)), nil
+	case float32:
+		return floatData(float64(value), 32)
+	case float64:
+		return floatData(value, 64)
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for key, element := range value {
+			data, err := convertData(element, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = data
+		}
+		return m, nil
+	case map[string]*Value:
+		m := make(map[string]interface{}, len(value))
+		for key, element := range value {
+			m[key], _ = toData(element)
+		}
+		return m, nil
+	case []interface{}:
+		s := make([]interface{}, len(value))
+		for i, element := range value {
+			data, err := convertData(element, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			s[i] = data
+		}
+		return s, nil
+	case []*Value:
+		s := make([]interface{}, len(value))
+		for i, element := range value {
+			s[i], _ = toData(element)
+		}
+		return s, nil
+	}
+
+	// Anything else is converted like encoding/json would marshal it
+	return reflectData(reflect.ValueOf(value), depth)
+}
+
+// Formats a float like encoding/json: without an exponent unless it is below 1e-6 or at least 1e21.
+func floatData(f float64, bitSize int) (interface{}, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("unsupported number %v", f)
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bitSize == 32 {
+			abs = float64(float32(abs))
+		}
+		if abs < 1e-6 || abs >= 1e21 {
+			format = 'e'
+		}
+	}
+	s := strconv.FormatFloat(f, format, -1, bitSize)
+	if n := len(s); format == 'e' && n >= 4 && s[n-4] == 'e' && s[n-3] == '-' && s[n-2] == '0' {
+		// 1e-07 is written as 1e-7
+		s = s[:n-2] + s[n-1:]
+	}
+	return json.Number(s), nil
+}
+
+func isNumber(s string) bool {
+	return s != "" && (s[0] == '-' || (s[0] >= '0' && s[0] <= '9')) && json.Valid([]byte(s))
+}
+
+// Deep copies decoded JSON data.
+func copyData(data interface{}) interface{} {
+	switch data := data.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(data))
+		for key, element := range data {
+			m[key] = copyData(element)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(data))
+		for i, element := range data {
+			s[i] = copyData(element)
+		}
+		return s
+	}
+	return data
+}
@@ -0,0 +1,298 @@
+package jason
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	o, err := NewObjectFromBytes([]byte(`{"name": "anton", "friends": [{"name": "alice"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sets := []struct {
+		path  string
+		value interface{}
+	}{
+		{"/name", "walter"},
+		{"/age", 51},
+		{"/address/street", "Street 42"},
+		{"/friends/0/age", 3.5},
+		{"/friends/-", map[string]interface{}{"name": "bob"}},
+		{"/friends/2", nil},
+		{"/matrix/0/0", true},
+	}
+	for _, s := range sets {
+		if err := o.Set(s.path, s.value); err != nil {
+			t.Errorf("%s: %v", s.path, err)
+		}
+	}
+	if err := o.Set("/copied", o.Map()["friends"]); err != nil {
+		t.Error(err)
+	}
+
+	b, err := o.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"address":{"street":"Street 42"},"age":51,"copied":[{"age":3.5,"name":"alice"},{"name":"bob"},null],` +
+		`"friends":[{"age":3.5,"name":"alice"},{"name":"bob"},null],"matrix":[[true]],"name":"walter"}`
+	if string(b) != want {
+		t.Errorf("got %s", b)
+	}
+
+	if name, err := o.GetString("name"); err != nil || name != "walter" {
+		t.Error(name, err)
+	}
+	if _, ok := o.Map()["age"]; !ok {
+		t.Error("Map() should contain the new key")
+	}
+	if age, err := o.GetInt64("age"); err != nil || age != 51 {
+		t.Error(age, err)
+	}
+
+	// The copy doesn't share state with the original.
+	if err := o.Set("/copied/0/name", "carol"); err != nil {
+		t.Fatal(err)
+	}
+	if name, _ := o.GetString("friends", "0", "name"); name != "alice" {
+		t.Error(name)
+	}
+}
+
+func TestSetErrors(t *testing.T) {
+	o, err := NewObjectFromBytes([]byte(`{"name": "anton", "list": [1, 2]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		path string
+		err  error
+	}{
+		{"/name/first", ErrNotObject},
+		{"/list/3", ErrIndexOutOfRange},
+		{"/list/x", ErrInvalidIndex},
+		{"name", ErrInvalidPointer},
+	}
+	for _, c := range cases {
+		err := o.Set(c.path, 1)
+		if e, ok := err.(*PointerError); !ok || e.Err != c.err {
+			t.Errorf("%s: unexpected error %v", c.path, err)
+		}
+	}
+	if err := o.Set("", "not an object"); err != ErrNotObject {
+		t.Error(err)
+	}
+	if err := o.Set("/bad", func() {}); err == nil {
+		t.Error("expected an error")
+	}
+
+	b, _ := o.MarshalJSON()
+	if string(b) != `{"list":[1,2],"name":"anton"}` {
+		t.Errorf("failed mutations should not change the object: %s", b)
+	}
+}
+
+func TestDeleteInsertAppend(t *testing.T) {
+	v, err := NewValueFromBytes([]byte(`[{"tags": ["b", "d"]}, 2, 3]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.Delete("/1"); err != nil {
+		t.Error(err)
+	}
+	if err := v.Insert("/0/tags", 0, "a"); err != nil {
+		t.Error(err)
+	}
+	if err := v.Insert("/0/tags", 2, "c"); err != nil {
+		t.Error(err)
+	}
+	if err := v.Append("/0/tags", "e"); err != nil {
+		t.Error(err)
+	}
+	if err := v.Append("/0/new", 1); err != nil {
+		t.Error(err)
+	}
+	if err := v.Delete("/0/new"); err != nil {
+		t.Error(err)
+	}
+
+	b, _ := v.Marshal()
+	if string(b) != `[{"tags":["a","b","c","d","e"]},3]` {
+		t.Errorf("got %s", b)
+	}
+
+	if err := v.Delete("/0/missing"); err == nil {
+		t.Error("expected an error")
+	} else if e := err.(*PointerError); e.Path != "/0/missing" || e.Err != (KeyNotFoundError{"missing"}) || e.Position.Column != 2 {
+		t.Error(err)
+	}
+	if err := v.Delete("/5"); err == nil {
+		t.Error("expected an error")
+	}
+	if err := v.Delete(""); err == nil {
+		t.Error("expected an error")
+	}
+	if err := v.Insert("/0/tags", 6, "x"); err == nil {
+		t.Error("expected an error")
+	}
+	if err := v.Append("/1", "x"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestSetRootObjectFromReader(t *testing.T) {
+	v, err := NewValueFromBytes([]byte(`{"a": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Set("/b", 2); err != nil {
+		t.Fatal(err)
+	}
+	o := v.Interface().(*Object)
+	if n, err := o.GetInt64("b"); err != nil || n != 2 {
+		t.Error(n, err)
+	}
+}
+
+func TestSetFloat(t *testing.T) {
+	floats := []interface{}{1e6, 2e20, 1e21, 123.456, 1e-6, 1e-7, -0.5, 0.0, float32(1e6), float32(0.1), float32(1e-7)}
+	for _, f := range floats {
+		v := &Value{}
+		if err := v.Set("/n", f); err != nil {
+			t.Fatal(err)
+		}
+		got, _ := v.Marshal()
+		want, _ := json.Marshal(map[string]interface{}{"n": f})
+		if string(got) != string(want) {
+			t.Errorf("Set(%v) = %s, want %s", f, got, want)
+		}
+	}
+
+	v := &Value{}
+	if err := v.Set("/n", 1e6); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := v.Get("n").Int64(); err != nil || n != 1000000 {
+		t.Errorf("Int64() = %d, %v", n, err)
+	}
+}
+
+func TestSetCycle(t *testing.T) {
+	m := map[string]interface{}{}
+	m["self"] = m
+	s := []interface{}{nil}
+	s[0] = s
+
+	for _, x := range []interface{}{m, s, []interface{}{map[string]interface{}{"m": m}}} {
+		v := &Value{}
+		var e *json.UnsupportedValueError
+		if err := v.Set("/x", x); !errors.As(err, &e) {
+			t.Errorf("Set(%T) error = %v, want *json.UnsupportedValueError", x, err)
+		}
+	}
+}
+
+func TestMutateThroughChild(t *testing.T) {
+	o, err := NewObjectFromBytes([]byte(`{"person": {"name": "anton"}, "tags": ["a", "b", "c"], "list": [[1]]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	person, _ := o.GetValue("person")
+	if err := person.Set("/age", 3); err != nil {
+		t.Fatal(err)
+	}
+	tags, _ := o.GetValue("tags")
+	if err := tags.Append("", "d"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tags.Insert("", 0, "z"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tags.Delete("/2"); err != nil {
+		t.Fatal(err)
+	}
+	inner := o.Value.Get("list").Get(0)
+	if err := inner.Append("", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := inner.Set("", "replaced"); err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := o.MarshalJSON()
+	want := `{"list":["replaced"],"person":{"age":3,"name":"anton"},"tags":["z","a","c","d"]}`
+	if string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+	if s, err := o.GetStringArray("tags"); err != nil || len(s) != 4 {
+		t.Error(s, err)
+	}
+
+	// Positions of the document follow the change
+	c, _ := o.GetValue("tags", "2")
+	if col := c.Position().Column; col != 50 {
+		t.Errorf("column of shifted element = %d, want 50", col)
+	}
+	if inserted, _ := o.GetValue("tags", "0"); inserted.Position().IsValid() {
+		t.Errorf("inserted element has position %v", inserted.Position())
+	}
+}
+
+func TestMutateUpdatesObjectMap(t *testing.T) {
+	o, _ := NewObjectFromBytes([]byte(`{"name": "a", "list": [1], "person": {"age": 1}}`))
+	if _, err := o.MarshalJSON(); err != nil {
+		t.Fatal(err)
+	}
+
+	name, _ := o.GetValue("name")
+	if err := name.Set("", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Map()["list"].Append("", 2); err != nil {
+		t.Fatal(err)
+	}
+	person, _ := o.GetObject("person")
+	if err := person.Map()["age"].Set("", 2); err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := o.MarshalJSON()
+	if want := `{"list":[1,2],"name":"b","person":{"age":2}}`; string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+	if s, err := o.Map()["name"].String(); err != nil || s != "b" {
+		t.Errorf("Map()[name] = %q, %v", s, err)
+	}
+	if age, err := person.GetInt64("age"); err != nil || age != 2 {
+		t.Errorf("age = %d, %v", age, err)
+	}
+}
+
+func TestMutateNestedLazy(t *testing.T) {
+	const doc = `{"a": {"b": 1}, "c": [[1]]}`
+	for _, parse := range []func([]byte) (*Value, error){NewValueFromBytes, NewLazyValue} {
+		v, err := parse([]byte(doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := v.Get("a").Get("b").Set("", 5); err != nil {
+			t.Fatal(err)
+		}
+		if err := v.Get("c").Get(0).Append("", 2); err != nil {
+			t.Fatal(err)
+		}
+		b, _ := v.Marshal()
+		if want := `{"a":{"b":5},"c":[[1,2]]}`; string(b) != want {
+			t.Errorf("got %s, want %s", b, want)
+		}
+		if n, err := v.Get("a").Get("b").Int64(); err != nil || n != 5 {
+			t.Errorf("b = %d, %v", n, err)
+		}
+	}
+}
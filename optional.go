@@ -0,0 +1,214 @@
+package jason
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// Reports whether the value is in the document.
+// Values returned by Get for missing members or indices, or for lookups in the wrong kind of value, don't exist.
+// A null value exists, see IsNull.
+// Example:
+//
+//	if !v.Get("server").Get("port").Exists() {
+//		...
+//	}
+func (v *Value) Exists() bool {
+	return v != nil && v.Err == nil && v.load() == nil
+}
+
+// Reports whether the value exists and is null.
+// Example:
+//
+//	cleared := v.Get("expires").IsNull()
+func (v *Value) IsNull() bool {
+	return v.Exists() && v.raw() == nil
+}
+
+// Reports whether the error is for a member or index that isn't in the document.
+func isMissing(err error) bool {
+	var notFound KeyNotFoundError
+	return errors.As(err, &notFound) || errors.Is(err, ErrIndexOutOfRange)
+}
+
+// Optional is a value that may be missing, null, of the wrong type or present. See GetOptional.
+type Optional[T any] struct {
+	value   T
+	missing bool
+	null    bool
+	err     error
+}
+
+// Gets the value at the path, made of member names and array indices, and converts it to T like Get.
+// Unlike Get, a missing value and null aren't errors, and the result tells them apart.
+// Example:
+//
+//	timeout := jason.GetOptional[int](v, "server", "timeout")
+//	switch {
+//	case timeout.IsMissing():
+//		// use the default
+//	case timeout.IsNull():
+//		// no timeout
+//	case timeout.Err() != nil:
+//		return timeout.Err()
+//	}
+//	seconds, err := jason.GetOptional[int](v, "server", "timeout").Or(30)
+func GetOptional[T any](v *Value, path ...any) Optional[T] {
+	for _, key := range path {
+		v = v.Get(key)
+	}
+	var o Optional[T]
+	if v.Err != nil {
+		if isMissing(v.Err) {
+			o.missing = true
+		} else {
+			o.err = v.Err
+		}
+		return o
+	}
+	if err := v.load(); err != nil {
+		o.err = err
+		return o
+	}
+	if v.raw() == nil {
+		o.null = true
+		return o
+	}
+	o.value, o.err = As[T](v)
+	return o
+}
+
+// Reports whether the value is not in the document.
+func (o Optional[T]) IsMissing() bool {
+	return o.missing
+}
+
+// Reports whether the value is null.
+func (o Optional[T]) IsNull() bool {
+	return o.null
+}
+
+// Reports whether the value is present and was converted to T.
+func (o Optional[T]) IsPresent() bool {
+	return !o.missing && !o.null && o.err == nil
+}
+
+// Returns why a value that is in the document couldn't be converted to T, e.g. because it has the wrong type.
+// Missing values and null are not errors.
+func (o Optional[T]) Err() error {
+	return o.err
+}
+
+// Returns the value and whether it is present. See IsPresent.
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.IsPresent()
+}
+
+// Returns the value if it is present and def if it is missing or null.
+// A value that can't be converted to T returns def together with the error.
+func (o Optional[T]) Or(def T) (T, error) {
+	if !o.IsPresent() {
+		return def, o.err
+	}
+	return o.value, nil
+}
+
+// Gets the value at key path for the Get<Type>Or methods.
+// Returns nil for a value that is missing or null.
+func (v *Object) getOptional(keys []string) (*Value, error) {
+	child, err := v.getPath(keys)
+	if isMissing(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := child.load(); err != nil {
+		return nil, err
+	}
+	if child.raw() == nil {
+		return nil, nil
+	}
+	return child, nil
+}
+
+// Gets the string at key path, or def if the value is missing or null.
+// Returns def and an error if the value is not a json string, instead of hiding the mistake.
+// Example:
+//
+//	host, err := o.GetStringOr("localhost", "server", "host")
+func (v *Object) GetStringOr(def string, keys ...string) (string, error) {
+	child, err := v.getOptional(keys)
+	if child == nil {
+		return def, err
+	}
+	s, err := child.String()
+	if err != nil {
+		return def, err
+	}
+	return s, nil
+}
+
+// Gets the number at key path, or def if the value is missing or null. See GetStringOr.
+// Example:
+//
+//	n, err := o.GetNumberOr("0", "limits", "max")
+func (v *Object) GetNumberOr(def json.Number, keys ...string) (json.Number, error) {
+	child, err := v.getOptional(keys)
+	if child == nil {
+		return def, err
+	}
+	n, err := child.Number()
+	if err != nil {
+		return def, err
+	}
+	return n, nil
+}
+
+// Gets the float64 at key path, or def if the value is missing or null. See GetStringOr.
+// Example:
+//
+//	ratio, err := o.GetFloat64Or(0.5, "sampling", "ratio")
+func (v *Object) GetFloat64Or(def float64, keys ...string) (float64, error) {
+	child, err := v.getOptional(keys)
+	if child == nil {
+		return def, err
+	}
+	f, err := child.Float64()
+	if err != nil {
+		return def, err
+	}
+	return f, nil
+}
+
+// Gets the int64 at key path, or def if the value is missing or null. See GetStringOr.
+// Example:
+//
+//	port, err := o.GetInt64Or(8080, "server", "port")
+func (v *Object) GetInt64Or(def int64, keys ...string) (int64, error) {
+	child, err := v.getOptional(keys)
+	if child == nil {
+		return def, err
+	}
+	i, err := child.Int64()
+	if err != nil {
+		return def, err
+	}
+	return i, nil
+}
+
+// Gets the bool at key path, or def if the value is missing or null. See GetStringOr.
+// Example:
+//
+//	verbose, err := o.GetBooleanOr(false, "log", "verbose")
+func (v *Object) GetBooleanOr(def bool, keys ...string) (bool, error) {
+	child, err := v.getOptional(keys)
+	if child == nil {
+		return def, err
+	}
+	b, err := child.Boolean()
+	if err != nil {
+		return def, err
+	}
+	return b, nil
+}
@@ -0,0 +1,122 @@
+package jason
+
+import (
+	"errors"
+	"testing"
+)
+
+const optionalDoc = `{
+	"name": "anton", "nick": null, "port": 8080, "ratio": 0.5, "debug": true,
+	"list": [1, null], "wrong": "yes"
+}`
+
+func TestExists(t *testing.T) {
+	v, _ := NewValueFromBytes([]byte(optionalDoc))
+	cases := []struct {
+		value          *Value
+		exists, isNull bool
+	}{
+		{v, true, false},
+		{v.Get("name"), true, false},
+		{v.Get("nick"), true, true},
+		{v.Get("list").Get(1), true, true},
+		{v.Get("missing"), false, false},
+		{v.Get("list").Get(5), false, false},
+		{v.Get("name").Get("first"), false, false},
+		{v.Get("missing").Get("deeper"), false, false},
+	}
+	for i, c := range cases {
+		if got := c.value.Exists(); got != c.exists {
+			t.Errorf("%d: Exists() = %v, want %v", i, got, c.exists)
+		}
+		if got := c.value.IsNull(); got != c.isNull {
+			t.Errorf("%d: IsNull() = %v, want %v", i, got, c.isNull)
+		}
+	}
+
+	l, _ := NewLazyValue([]byte(optionalDoc))
+	if !l.Get("nick").IsNull() || l.Get("missing").Exists() {
+		t.Error("lazy values should exist like parsed ones")
+	}
+}
+
+func TestGetOr(t *testing.T) {
+	o, _ := NewObjectFromBytes([]byte(optionalDoc))
+
+	if s, err := o.GetStringOr("x", "name"); err != nil || s != "anton" {
+		t.Errorf("present: %q, %v", s, err)
+	}
+	if s, err := o.GetStringOr("x", "nick"); err != nil || s != "x" {
+		t.Errorf("null: %q, %v", s, err)
+	}
+	if s, err := o.GetStringOr("x", "missing", "deeper"); err != nil || s != "x" {
+		t.Errorf("missing: %q, %v", s, err)
+	}
+	if s, err := o.GetStringOr("x", "port"); !errors.Is(err, ErrNotString) || s != "x" {
+		t.Errorf("wrong type: %q, %v", s, err)
+	}
+	if s, err := o.GetStringOr("x", "name", "first"); !errors.Is(err, ErrNotObject) || s != "x" {
+		t.Errorf("lookup in a string: %q, %v", s, err)
+	}
+
+	if n, err := o.GetInt64Or(1, "port"); err != nil || n != 8080 {
+		t.Errorf("GetInt64Or = %d, %v", n, err)
+	}
+	if n, err := o.GetInt64Or(1, "list", "5"); err != nil || n != 1 {
+		t.Errorf("GetInt64Or out of range = %d, %v", n, err)
+	}
+	if n, err := o.GetInt64Or(1, "list", "1"); err != nil || n != 1 {
+		t.Errorf("GetInt64Or null element = %d, %v", n, err)
+	}
+	if f, err := o.GetFloat64Or(1, "ratio"); err != nil || f != 0.5 {
+		t.Errorf("GetFloat64Or = %v, %v", f, err)
+	}
+	if n, err := o.GetNumberOr("7", "missing"); err != nil || n != "7" {
+		t.Errorf("GetNumberOr = %v, %v", n, err)
+	}
+	if b, err := o.GetBooleanOr(false, "debug"); err != nil || !b {
+		t.Errorf("GetBooleanOr = %v, %v", b, err)
+	}
+	var pe *PathError
+	if b, err := o.GetBooleanOr(false, "wrong"); !errors.As(err, &pe) || pe.Path != "/wrong" || b {
+		t.Errorf("GetBooleanOr wrong type = %v, %v", b, err)
+	}
+}
+
+func TestGetOptional(t *testing.T) {
+	v, _ := NewValueFromBytes([]byte(optionalDoc))
+	cases := []struct {
+		path                        []any
+		missing, null, present, err bool
+	}{
+		{[]any{"port"}, false, false, true, false},
+		{[]any{"nick"}, false, true, false, false},
+		{[]any{"missing"}, true, false, false, false},
+		{[]any{"list", 7}, true, false, false, false},
+		{[]any{"list", 1}, false, true, false, false},
+		{[]any{"wrong"}, false, false, false, true},
+		{[]any{"name", "first"}, false, false, false, true},
+	}
+	for _, c := range cases {
+		o := GetOptional[int](v, c.path...)
+		if o.IsMissing() != c.missing || o.IsNull() != c.null || o.IsPresent() != c.present || (o.Err() != nil) != c.err {
+			t.Errorf("%v: missing %v, null %v, present %v, err %v", c.path, o.IsMissing(), o.IsNull(), o.IsPresent(), o.Err())
+		}
+	}
+
+	if port, ok := GetOptional[uint16](v, "port").Get(); !ok || port != 8080 {
+		t.Errorf("Get() = %d, %v", port, ok)
+	}
+	if n, err := GetOptional[int](v, "nick").Or(3); err != nil || n != 3 {
+		t.Errorf("Or for null = %d, %v", n, err)
+	}
+	if n, err := GetOptional[int](v, "port").Or(3); err != nil || n != 8080 {
+		t.Errorf("Or for present = %d, %v", n, err)
+	}
+	if n, err := GetOptional[int](v, "wrong").Or(3); !errors.Is(err, ErrNotNumber) || n != 3 {
+		t.Errorf("Or for wrong type = %d, %v", n, err)
+	}
+	if n, err := GetOptional[int8](v, "port").Or(3); err == nil || n != 3 {
+		t.Errorf("Or for overflow = %d, %v", n, err)
+	}
+}
@@ -0,0 +1,164 @@
+package jason
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// Returns the member names of the object in document order.
+// Members added later follow in the order they were set. The keys of objects that weren't
+// parsed, e.g. built ones, are sorted. A repeated name is listed where it first appeared.
+// Example:
+//
+//	for _, key := range o.Keys() {
+//		fmt.Println(key)
+//	}
+func (v *Object) Keys() []string {
+	if v.lazy != nil {
+		return v.lazyKeys()
+	}
+	data, _ := v.raw().(map[string]interface{})
+	return orderedKeys(data, v.sourceNode())
+}
+
+// Calls fn for every member of the object in the order of Keys, until fn returns false.
+// Example:
+//
+//	o.Range(func(key string, value *jason.Value) bool {
+//		fmt.Println(key, value)
+//		return true
+//	})
+func (v *Object) Range(fn func(key string, value *Value) bool) {
+	m := v.Map()
+	for _, key := range v.Keys() {
+		if !fn(key, m[key]) {
+			return
+		}
+	}
+}
+
+// Returns the node of the value if it was parsed with PreserveOrder.
+// The flag is checked on the value the others were read from first, so values parsed
+// without PreserveOrder don't pay for looking up their node.
+func (v *Value) orderedNode() *parseNode {
+	root := v
+	for root.node == nil && root.parent != nil {
+		root = root.parent
+	}
+	if root.node == nil || !root.node.ordered {
+		return nil
+	}
+	return v.sourceNode()
+}
+
+// Returns the keys of the data in the order of the node, followed by the keys it doesn't know, sorted.
+func orderedKeys(data map[string]interface{}, node *parseNode) []string {
+	keys := make([]string, 0, len(data))
+	seen := make(map[string]bool, len(data))
+	if node != nil {
+		for _, key := range node.keys {
+			if _, ok := data[key]; ok && !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	known := len(keys)
+	for key := range data {
+		if !seen[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys[known:])
+	return keys
+}
+
+// Marshals the data like encoding/json, but with object members in the order of Keys.
+func marshalOrdered(data interface{}, node *parseNode) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeOrdered(&buf, data, node); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeOrdered(buf *bytes.Buffer, data interface{}, node *parseNode) error {
+	switch data := data.(type) {
+	case map[string]interface{}:
+		if data == nil {
+			buf.WriteString("null")
+			return nil
+		}
+		buf.WriteByte('{')
+		written := 0
+		member := func(key string, child *parseNode) error {
+			if written > 0 {
+				buf.WriteByte(',')
+			}
+			written++
+			name, _ := json.Marshal(key)
+			buf.Write(name)
+			buf.WriteByte(':')
+			return writeOrdered(buf, data[key], child)
+		}
+		seen := make(map[string]bool, len(data))
+		if node != nil {
+			for i, key := range node.keys {
+				if _, ok := data[key]; !ok || seen[key] {
+					continue
+				}
+				seen[key] = true
+				child := node.children[i]
+				if j := node.member(key); j != i {
+					// A repeated name goes where it first appeared, with the node of the last one
+					child = node.children[j]
+				}
+				if err := member(key, child); err != nil {
+					return err
+				}
+			}
+		}
+		if written < len(data) {
+			rest := make([]string, 0, len(data)-written)
+			for key := range data {
+				if !seen[key] {
+					rest = append(rest, key)
+				}
+			}
+			sort.Strings(rest)
+			for _, key := range rest {
+				if err := member(key, nil); err != nil {
+					return err
+				}
+			}
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		if data == nil {
+			buf.WriteString("null")
+			return nil
+		}
+		buf.WriteByte('[')
+		for i, element := range data {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			var child *parseNode
+			if node != nil && node.keys == nil && i < len(node.children) {
+				child = node.children[i]
+			}
+			if err := writeOrdered(buf, element, child); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		b, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package jason
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const orderedDoc = `{"zeta": 1, "alpha": {"y": [{"b": 1, "a": 2}], "x": null}, "mid": "<&>", "beta": []}`
+
+func TestPreserveOrder(t *testing.T) {
+	o, err := ParseOptions{PreserveOrder: true}.NewObjectFromBytes([]byte(orderedDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Strings are escaped like encoding/json does
+	want := `{"zeta":1,"alpha":{"y":[{"b":1,"a":2}],"x":null},"mid":"\u003c\u0026\u003e","beta":[]}`
+	if b, _ := o.MarshalJSON(); string(b) != want {
+		t.Error(string(b))
+	}
+	if o.String() != want {
+		t.Error(o.String())
+	}
+	if b, _ := json.Marshal(o); string(b) != want {
+		t.Error(string(b))
+	}
+	alpha, _ := o.GetValue("alpha")
+	if b, _ := alpha.Marshal(); string(b) != `{"y":[{"b":1,"a":2}],"x":null}` {
+		t.Error(string(b))
+	}
+
+	v, _ := ParseOptions{PreserveOrder: true}.NewValue(strings.NewReader(orderedDoc))
+	v.Set("/zeta", 2)
+	v.Set("/new", map[string]interface{}{"d": 1, "c": 2})
+	v.Set("/another", true)
+	v.Delete("/mid")
+	v.Insert("/alpha/y", 0, "first")
+	v.Set("/alpha/y/-", "last")
+	want = `{"zeta":2,"alpha":{"y":["first",{"b":1,"a":2},"last"],"x":null},"beta":[],"new":{"c":2,"d":1},"another":true}`
+	if b, _ := v.Marshal(); string(b) != want {
+		t.Error(string(b))
+	}
+	v.Set("/mid", 1)
+	if o, _ := v.Object(); !reflect.DeepEqual(o.Keys(), []string{"zeta", "alpha", "beta", "new", "another", "mid"}) {
+		t.Error(o.Keys())
+	}
+
+	// Without the option objects still marshal sorted
+	plain, _ := NewObjectFromBytes([]byte(orderedDoc))
+	if b, _ := plain.MarshalJSON(); !strings.HasPrefix(string(b), `{"alpha":{"x":null,"y":[{"a":2,"b":1}]}`) {
+		t.Error(string(b))
+	}
+}
+
+func TestKeys(t *testing.T) {
+	cases := []struct {
+		name string
+		o    func() (*Object, error)
+		want []string
+	}{
+		{"parsed", func() (*Object, error) { return NewObjectFromBytes([]byte(`{"b":1,"a":2,"b":3,"c":4}`)) }, []string{"b", "a", "c"}},
+		{"reader", func() (*Object, error) { return NewObjectFromReader(strings.NewReader(`{"b":1,"a":2}`)) }, []string{"b", "a"}},
+		{"lazy", func() (*Object, error) { return NewLazyObject([]byte(`{"b":1,"a":2,"b":3}`)) }, []string{"b", "a"}},
+		{"built", func() (*Object, error) { return NewObjectBuilder().Num("b", 1).Num("a", 2).Object() }, []string{"a", "b"}},
+		{"empty", func() (*Object, error) { return NewObjectFromBytes([]byte(`{}`)) }, []string{}},
+	}
+	for _, c := range cases {
+		o, err := c.o()
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if keys := o.Keys(); !reflect.DeepEqual(keys, c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, keys, c.want)
+		}
+	}
+
+	o, _ := NewObjectFromBytes([]byte(`{"z":{"b":1,"a":2}}`))
+	z, _ := o.GetObject("z")
+	if !reflect.DeepEqual(z.Keys(), []string{"b", "a"}) {
+		t.Error(z.Keys())
+	}
+}
+
+func TestRange(t *testing.T) {
+	o, _ := NewObjectFromBytes([]byte(`{"c":1,"a":2,"b":3}`))
+	var keys []string
+	var sum int64
+	o.Range(func(key string, value *Value) bool {
+		keys = append(keys, key)
+		n, _ := value.Int64()
+		sum += n
+		return key != "a"
+	})
+	if !reflect.DeepEqual(keys, []string{"c", "a"}) || sum != 3 {
+		t.Error(keys, sum)
+	}
+}
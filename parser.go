@@ -0,0 +1,839 @@
+package jason
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Position is a location in parsed JSON source.
+type Position struct {
+	Offset int // Byte offset, starting at 0
+	Line   int // Line number, starting at 1
+	Column int // Column number in characters, starting at 1
+}
+
+// Reports whether the position is known.
+// Values that were not parsed from source, e.g. built or set values, have no position.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Position) String() string {
+	if !p.IsValid() {
+		return "unknown position"
+	}
+	return fmt.Sprintf("line %d, column %d", p.Line, p.Column)
+}
+
+// SyntaxError is returned when the input is not valid JSON.
+// Earlier versions returned *json.SyntaxError, its Offset is Position.Offset here.
+type SyntaxError struct {
+	Msg      string
+	Position Position
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Position, e.Msg)
+}
+
+// Records where a parsed value, and the member name it was read under, started.
+// Children are kept in document order, with their member names for objects.
+// keys is nil for anything but objects.
+type parseNode struct {
+	pos      Position
+	end      int // Offset after the value
+	keyPos   Position
+	keys     []string
+	children []*parseNode
+	ordered  bool // Parsed with PreserveOrder
+
+	index      map[string]int           // Index of each member name in keys, for objects with many members
+	duplicates map[string][]interface{} // Every value of repeated member names, with DuplicateCollect
+}
+
+// Objects with more members than this get an index, smaller ones are searched.
+const indexedMembers = 16
+
+// Returns the node of a member or element, or nil if it isn't known.
+func (n *parseNode) child(key string) *parseNode {
+	if n == nil {
+		return nil
+	}
+	if n.keys != nil {
+		if i := n.member(key); i >= 0 {
+			return n.children[i]
+		}
+		return nil
+	}
+	i, err := strconv.Atoi(key)
+	if err != nil || i < 0 || i >= len(n.children) {
+		return nil
+	}
+	return n.children[i]
+}
+
+// Returns the index of the last member with the name in keys, or -1.
+// The last one matches the data, where a repeated name overwrites the earlier values.
+func (n *parseNode) member(key string) int {
+	if n.index != nil {
+		if i, ok := n.index[key]; ok {
+			return i
+		}
+		return -1
+	}
+	for i := len(n.keys) - 1; i >= 0; i-- {
+		if n.keys[i] == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Builds the index of the member names, or drops it if there are only a few.
+func (n *parseNode) indexMembers() {
+	n.index = nil
+	if len(n.keys) <= indexedMembers {
+		return
+	}
+	n.index = make(map[string]int, len(n.keys))
+	for i, key := range n.keys {
+		n.index[key] = i
+	}
+}
+
+// How a value was changed, see parseNode.update.
+type nodeChange int
+
+const (
+	nodeSet    nodeChange = iota // The value was replaced or added
+	nodeDelete                   // The member or element was removed
+	nodeInsert                   // The element was inserted, shifting later elements up
+)
+
+// Updates the nodes after the value at the tokens was changed.
+// The changed value loses its position, while members and elements keep their order,
+// and added members go last. Returns nil if the root itself was replaced.
+func (n *parseNode) update(tokens []string, change nodeChange) *parseNode {
+	if n == nil || len(tokens) == 0 {
+		return nil
+	}
+	parent := n
+	for i, token := range tokens[:len(tokens)-1] {
+		child := parent.child(token)
+		if child == nil {
+			// The rest of the path was created by the change
+			tokens, change = tokens[:i+1], nodeSet
+			break
+		}
+		parent = child
+	}
+	last := tokens[len(tokens)-1]
+	fresh := &parseNode{ordered: n.ordered}
+
+	if parent.keys != nil {
+		delete(parent.duplicates, last)
+		switch change {
+		case nodeSet:
+			if i := parent.member(last); i >= 0 {
+				parent.children[i] = fresh
+				return n
+			}
+			parent.keys = append(parent.keys, last)
+			parent.children = append(parent.children, fresh)
+			if parent.index != nil {
+				parent.index[last] = len(parent.keys) - 1
+			} else if len(parent.keys) > indexedMembers {
+				parent.indexMembers()
+			}
+		case nodeDelete:
+			keys, children := parent.keys[:0], parent.children[:0]
+			for i, key := range parent.keys {
+				if key != last {
+					keys = append(keys, key)
+					children = append(children, parent.children[i])
+				}
+			}
+			parent.keys, parent.children = keys, children
+			if parent.index != nil {
+				parent.indexMembers()
+			}
+		}
+		return n
+	}
+
+	i := len(parent.children)
+	if last != "-" {
+		var err error
+		if i, err = strconv.Atoi(last); err != nil || i < 0 || i > len(parent.children) {
+			return n
+		}
+	}
+	switch {
+	case change == nodeSet && i < len(parent.children):
+		parent.children[i] = fresh
+	case change == nodeSet || change == nodeInsert:
+		parent.children = append(parent.children, nil)
+		copy(parent.children[i+1:], parent.children[i:])
+		parent.children[i] = fresh
+	case change == nodeDelete && i < len(parent.children):
+		parent.children = append(parent.children[:i], parent.children[i+1:]...)
+	}
+	return n
+}
+
+// Returns the node of the value, looking it up through the values it was read from.
+func (v *Value) sourceNode() *parseNode {
+	if v.node != nil || v.parent == nil {
+		return v.node
+	}
+	return v.parent.sourceNode().child(v.key)
+}
+
+// Returns the position where the value started in the source.
+// The position is only known for values parsed with NewValue, NewValueFromReader and the
+// other constructors, and for the values read from them.
+// Example:
+//
+//	port := v.Get("server").Get("port")
+//	if _, err := port.Int64(); err != nil {
+//		log.Printf("%s: port must be a number", port.Position())
+//	}
+func (v *Value) Position() Position {
+	if v.lazy != nil {
+		return v.lazy.doc.position(v.lazy.start)
+	}
+	if node := v.sourceNode(); node != nil {
+		return node.pos
+	}
+	return Position{}
+}
+
+// Returns the position of the member name the value was read under.
+// Invalid for array elements and values without a known position.
+func (v *Value) KeyPosition() Position {
+	if v.lazy != nil {
+		return v.lazy.keyPos
+	}
+	if node := v.sourceNode(); node != nil {
+		return node.keyPos
+	}
+	return Position{}
+}
+
+// Parses JSON from a reader or a byte slice while keeping track of positions.
+// It produces the same data as encoding/json with UseNumber.
+type parser struct {
+	r   io.Reader
+	buf []byte
+	i   int      // Index of the next byte in buf
+	pos Position // Position of the next byte
+	err error    // Read error, io.EOF at the end of the input
+
+	nodes   []parseNode // Allocated in blocks, since there is one for every value
+	scratch []byte
+
+	relaxed    bool
+	ordered    bool
+	duplicates DuplicatePolicy
+	limits     Limits
+	depth      int
+	read       int // Bytes read from r
+}
+
+func newParser(r io.Reader) *parser {
+	p := &parser{r: r, pos: Position{Line: 1, Column: 1}}
+	p.setLimits(Limits{})
+	return p
+}
+
+func newBytesParser(b []byte) *parser {
+	p := &parser{buf: b, pos: Position{Line: 1, Column: 1}, err: io.EOF}
+	p.setLimits(Limits{})
+	return p
+}
+
+// Returns the next byte without consuming it. ok is false at the end of the input.
+func (p *parser) peek() (c byte, ok bool) {
+	if p.i < len(p.buf) {
+		return p.buf[p.i], true
+	}
+	if !p.fill() {
+		return 0, false
+	}
+	return p.buf[p.i], true
+}
+
+// Reads more input, dropping what was already consumed.
+func (p *parser) fill() bool {
+	if p.err != nil {
+		return false
+	}
+	if p.i > 0 {
+		p.buf = p.buf[:copy(p.buf, p.buf[p.i:])]
+		p.i = 0
+	}
+	if cap(p.buf)-len(p.buf) < 512 {
+		buf := make([]byte, len(p.buf), 2*cap(p.buf)+4096)
+		copy(buf, p.buf)
+		p.buf = buf
+	}
+	for {
+		n, err := p.r.Read(p.buf[len(p.buf):cap(p.buf)])
+		p.buf = p.buf[:len(p.buf)+n]
+		p.read += n
+		if max := p.limits.MaxBytes; max > 0 && p.read > max {
+			over := p.read - max
+			p.buf = p.buf[:len(p.buf)-over]
+			n -= over
+			err = &LimitError{Limit: "MaxBytes", Max: max}
+		}
+		if err != nil {
+			p.err = err
+		}
+		if n > 0 {
+			return true
+		}
+		if err != nil {
+			return false
+		}
+	}
+}
+
+// Consumes the next byte, which must have been peeked.
+func (p *parser) advance() {
+	c := p.buf[p.i]
+	p.i++
+	p.pos.Offset++
+	if c == '\n' {
+		p.pos.Line++
+		p.pos.Column = 1
+	} else if c&0xC0 != 0x80 {
+		// UTF-8 continuation bytes don't start a new character
+		p.pos.Column++
+	}
+}
+
+func (p *parser) skipSpace() {
+	for {
+		c, ok := p.peek()
+		switch {
+		case !ok:
+			return
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			p.advance()
+		case !p.relaxed:
+			return
+		case c == '\v' || c == '\f':
+			p.advance()
+		case c != '/' || !p.skipComment():
+			return
+		}
+	}
+}
+
+func (p *parser) syntaxError(format string, args ...interface{}) error {
+	return &SyntaxError{Msg: fmt.Sprintf(format, args...), Position: p.pos}
+}
+
+// Error for input that ended in the middle of a value.
+func (p *parser) unexpectedEnd() error {
+	if p.err != nil && p.err != io.EOF {
+		return p.readError()
+	}
+	return p.syntaxError("unexpected end of JSON input")
+}
+
+// Returns the error that ended the input. Input cut off at MaxBytes ends at the current position.
+func (p *parser) readError() error {
+	if e, ok := p.err.(*LimitError); ok && !e.Position.IsValid() {
+		e.Position = p.pos
+	}
+	return p.err
+}
+
+// Adds the reference token of the member or element an error occurred in to the path of the error.
+func errorIn(err error, token string) error {
+	switch e := err.(type) {
+	case *DuplicateKeyError:
+		e.Path = "/" + escapePointerToken(token) + e.Path
+	case *LimitError:
+		// Prepending to Path would copy it once for every level of nesting
+		e.tokens = append(e.tokens, token)
+	}
+	return err
+}
+
+// Parses the next top-level value. Returns io.EOF if only whitespace is left.
+func (p *parser) parse() (interface{}, *parseNode, error) {
+	p.skipSpace()
+	if _, ok := p.peek(); !ok {
+		if p.err != nil && p.err != io.EOF {
+			return nil, nil, p.readError()
+		}
+		return nil, nil, io.EOF
+	}
+	data, node, err := p.value()
+	if e, ok := err.(*LimitError); ok {
+		e.setPath()
+	}
+	return data, node, err
+}
+
+// Skips whitespace and the record separators of JSON text sequences (RFC 7464).
+func (p *parser) skipRecordSeparators() {
+	for {
+		p.skipSpace()
+		if c, ok := p.peek(); !ok || c != 0x1E {
+			return
+		}
+		p.advance()
+	}
+}
+
+// Checks that only whitespace is left after a value.
+func (p *parser) end() error {
+	p.skipSpace()
+	if c, ok := p.peek(); ok {
+		return p.syntaxError("invalid character %q after top-level value", c)
+	}
+	if p.err != nil && p.err != io.EOF {
+		return p.readError()
+	}
+	return nil
+}
+
+func (p *parser) value() (interface{}, *parseNode, error) {
+	c, ok := p.peek()
+	if !ok {
+		return nil, nil, p.unexpectedEnd()
+	}
+
+	if len(p.nodes) == 0 {
+		p.nodes = make([]parseNode, 128)
+	}
+	n := &p.nodes[0]
+	p.nodes = p.nodes[1:]
+	n.pos = p.pos
+	n.ordered = p.ordered
+
+	if c == '{' || c == '[' {
+		if p.depth++; p.limits.MaxDepth > 0 && p.depth > p.limits.MaxDepth {
+			return nil, nil, p.limitError("MaxDepth", p.limits.MaxDepth, p.pos)
+		}
+	}
+
+	var data interface{}
+	var err error
+	switch {
+	case c == '{':
+		if data, err = p.object(n); err == nil {
+			n.indexMembers()
+		}
+		p.depth--
+	case c == '[':
+		data, err = p.array(n)
+		p.depth--
+	case c == '"' || (c == '\'' && p.relaxed):
+		data, err = p.string()
+	case c == '-' || (c >= '0' && c <= '9') || (p.relaxed && (c == '+' || c == '.' || c == 'I' || c == 'N')):
+		data, err = p.number()
+	case c == 't':
+		data, err = true, p.literal("true")
+	case c == 'f':
+		data, err = false, p.literal("false")
+	case c == 'n':
+		data, err = nil, p.literal("null")
+	default:
+		err = p.syntaxError("invalid character %q looking for beginning of value", c)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	n.end = p.pos.Offset
+	return data, n, nil
+}
+
+func (p *parser) object(n *parseNode) (interface{}, error) {
+	p.advance()
+	m := make(map[string]interface{})
+	n.keys = []string{}
+
+	p.skipSpace()
+	if c, ok := p.peek(); ok && c == '}' {
+		p.advance()
+		return m, nil
+	}
+
+	for members := 0; ; members++ {
+		c, ok := p.peek()
+		if !ok {
+			return nil, p.unexpectedEnd()
+		}
+		if c != '"' && !(p.relaxed && (c == '\'' || isIdentifierStart(c))) {
+			return nil, p.syntaxError("invalid character %q looking for beginning of object key string", c)
+		}
+		if max := p.limits.MaxObjectKeys; max > 0 && members >= max {
+			return nil, p.limitError("MaxObjectKeys", max, p.pos)
+		}
+		keyPos := p.pos
+		var key interface{}
+		var err error
+		if c == '"' || c == '\'' {
+			key, err = p.string()
+		} else {
+			key, err = p.identifier()
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := key.(string)
+		var dup bool
+		if p.duplicates != DuplicateLastWins {
+			_, dup = m[name]
+		}
+		if dup && p.duplicates == DuplicateError {
+			return nil, n.duplicateKeyError(name, keyPos)
+		}
+
+		p.skipSpace()
+		if c, ok = p.peek(); !ok {
+			return nil, p.unexpectedEnd()
+		}
+		if c != ':' {
+			return nil, p.syntaxError("invalid character %q after object key", c)
+		}
+		p.advance()
+		p.skipSpace()
+
+		value, child, err := p.value()
+		if err != nil {
+			return nil, errorIn(err, name)
+		}
+		if !dup || p.duplicates != DuplicateFirstWins {
+			if dup {
+				n.collect(name, m[name], value)
+			}
+			child.keyPos = keyPos
+			m[name] = value
+			n.keys = append(n.keys, name)
+			n.children = append(n.children, child)
+		}
+
+		p.skipSpace()
+		if c, ok = p.peek(); !ok {
+			return nil, p.unexpectedEnd()
+		}
+		if c != ',' && c != '}' {
+			return nil, p.syntaxError("invalid character %q after object key:value pair", c)
+		}
+		p.advance()
+		if c == '}' {
+			return m, nil
+		}
+		p.skipSpace()
+		if c, ok := p.peek(); ok && c == '}' && p.relaxed {
+			// Trailing comma
+			p.advance()
+			return m, nil
+		}
+	}
+}
+
+func (p *parser) array(n *parseNode) (interface{}, error) {
+	p.advance()
+	a := make([]interface{}, 0)
+
+	p.skipSpace()
+	if c, ok := p.peek(); ok && c == ']' {
+		p.advance()
+		return a, nil
+	}
+
+	for {
+		if max := p.limits.MaxArrayElements; max > 0 && len(a) >= max {
+			return nil, p.limitError("MaxArrayElements", max, p.pos)
+		}
+		value, child, err := p.value()
+		if err != nil {
+			return nil, errorIn(err, strconv.Itoa(len(a)))
+		}
+		a = append(a, value)
+		n.children = append(n.children, child)
+
+		p.skipSpace()
+		c, ok := p.peek()
+		if !ok {
+			return nil, p.unexpectedEnd()
+		}
+		if c != ',' && c != ']' {
+			return nil, p.syntaxError("invalid character %q after array element", c)
+		}
+		p.advance()
+		if c == ']' {
+			return a, nil
+		}
+		p.skipSpace()
+		if c, ok := p.peek(); ok && c == ']' && p.relaxed {
+			// Trailing comma
+			p.advance()
+			return a, nil
+		}
+	}
+}
+
+func (p *parser) literal(name string) error {
+	for i := 0; i < len(name); i++ {
+		c, ok := p.peek()
+		if !ok {
+			return p.unexpectedEnd()
+		}
+		if c != name[i] {
+			return p.syntaxError("invalid character %q in literal %s", c, name)
+		}
+		p.advance()
+	}
+	return nil
+}
+
+func (p *parser) number() (interface{}, error) {
+	start := p.pos
+	max := p.limits.MaxNumberLength
+	b := p.scratch[:0]
+	digits := func() int {
+		count := 0
+		for {
+			c, ok := p.peek()
+			if !ok || c < '0' || c > '9' || (max > 0 && len(b) > max) {
+				return count
+			}
+			b = append(b, c)
+			p.advance()
+			count++
+		}
+	}
+	expectDigits := func() error {
+		if digits() > 0 {
+			return nil
+		}
+		if c, ok := p.peek(); ok {
+			return p.syntaxError("invalid character %q in numeric literal", c)
+		}
+		return p.unexpectedEnd()
+	}
+
+	if c, _ := p.peek(); c == '-' || (c == '+' && p.relaxed) {
+		if c == '-' {
+			b = append(b, c)
+		}
+		p.advance()
+	}
+	leadingDot := false
+	c, ok := p.peek()
+	switch {
+	case !ok:
+		return nil, p.unexpectedEnd()
+	case c == '0':
+		b = append(b, c)
+		p.advance()
+		if c, ok := p.peek(); ok && (c == 'x' || c == 'X') && p.relaxed {
+			return p.hexNumber(string(b[:len(b)-1]), start)
+		}
+	case c >= '1' && c <= '9':
+		digits()
+	case p.relaxed && (c == 'I' || c == 'N'):
+		return p.namedNumber(c)
+	case p.relaxed && c == '.':
+		b = append(b, '0')
+		leadingDot = true
+	default:
+		return nil, p.syntaxError("invalid character %q in numeric literal", c)
+	}
+
+	if c, ok := p.peek(); ok && c == '.' {
+		b = append(b, c)
+		p.advance()
+		if p.relaxed && !leadingDot {
+			if digits() == 0 {
+				// Trailing decimal point
+				b = append(b, '0')
+			}
+		} else if err := expectDigits(); err != nil {
+			return nil, err
+		}
+	}
+	if c, ok := p.peek(); ok && (c == 'e' || c == 'E') {
+		b = append(b, c)
+		p.advance()
+		if c, ok := p.peek(); ok && (c == '+' || c == '-') {
+			b = append(b, c)
+			p.advance()
+		}
+		if err := expectDigits(); err != nil {
+			return nil, err
+		}
+	}
+	p.scratch = b
+	if max > 0 && len(b) > max {
+		return nil, p.limitError("MaxNumberLength", max, start)
+	}
+	return json.Number(b), nil
+}
+
+func (p *parser) string() (interface{}, error) {
+	start := p.pos
+	max := p.limits.MaxStringLength
+	quote := p.buf[p.i]
+	p.advance()
+
+	// Most strings have no escapes and end within the buffer, so they can be sliced out.
+	for i := p.i; i < len(p.buf); i++ {
+		c := p.buf[i]
+		if c == quote {
+			s := p.buf[p.i:i]
+			if max > 0 && len(s) > max {
+				return nil, p.limitError("MaxStringLength", max, start)
+			}
+			p.i = i + 1
+			p.pos.Offset += len(s) + 1
+			p.pos.Column++
+			for _, c := range s {
+				if c&0xC0 != 0x80 {
+					p.pos.Column++
+				}
+			}
+			return validUTF8(s), nil
+		}
+		if c == '\\' || c < 0x20 {
+			break
+		}
+	}
+
+	b := p.scratch[:0]
+	defer func() { p.scratch = b }()
+	for {
+		if max > 0 && len(b) > max {
+			return nil, p.limitError("MaxStringLength", max, start)
+		}
+		c, ok := p.peek()
+		if !ok {
+			return nil, p.unexpectedEnd()
+		}
+		switch {
+		case c == quote:
+			p.advance()
+			return validUTF8(b), nil
+		case c == '\\':
+			p.advance()
+			var err error
+			if b, err = p.escape(b); err != nil {
+				return nil, err
+			}
+		case c < 0x20:
+			return nil, p.syntaxError("invalid character %q in string literal", c)
+		default:
+			b = append(b, c)
+			p.advance()
+		}
+	}
+}
+
+// Reads an escape sequence after the backslash and appends the escaped character to b.
+func (p *parser) escape(b []byte) ([]byte, error) {
+	c, ok := p.peek()
+	if !ok {
+		return nil, p.unexpectedEnd()
+	}
+	pos := p.pos
+	p.advance()
+	switch c {
+	case '"', '\\', '/':
+		return append(b, c), nil
+	case 'b':
+		return append(b, '\b'), nil
+	case 'f':
+		return append(b, '\f'), nil
+	case 'n':
+		return append(b, '\n'), nil
+	case 'r':
+		return append(b, '\r'), nil
+	case 't':
+		return append(b, '\t'), nil
+	case 'u':
+	default:
+		if p.relaxed {
+			return p.relaxedEscape(b, c, pos)
+		}
+		return nil, &SyntaxError{Msg: fmt.Sprintf("invalid character %q in string escape code", c), Position: pos}
+	}
+
+	r, err := p.hex4()
+	if err != nil {
+		return nil, err
+	}
+	// A surrogate must be followed by its pair, otherwise it is replaced like encoding/json does.
+	for utf16.IsSurrogate(r) {
+		if c, ok := p.peek(); !ok || c != '\\' {
+			return appendRune(b, utf8.RuneError), nil
+		}
+		p.advance()
+		if c, ok := p.peek(); !ok || c != 'u' {
+			return p.escape(appendRune(b, utf8.RuneError))
+		}
+		p.advance()
+		r2, err := p.hex4()
+		if err != nil {
+			return nil, err
+		}
+		if pair := utf16.DecodeRune(r, r2); pair != utf8.RuneError {
+			return appendRune(b, pair), nil
+		}
+		b = appendRune(b, utf8.RuneError)
+		r = r2
+	}
+	return appendRune(b, r), nil
+}
+
+func (p *parser) hex4() (rune, error) {
+	var r rune
+	for i := 0; i < 4; i++ {
+		c, ok := p.peek()
+		if !ok {
+			return 0, p.unexpectedEnd()
+		}
+		d, ok := hexDigit(c)
+		if !ok {
+			return 0, p.syntaxError("invalid character %q in \\u hexadecimal character escape", c)
+		}
+		r = r<<4 | rune(d)
+		p.advance()
+	}
+	return r, nil
+}
+
+func appendRune(b []byte, r rune) []byte {
+	var buf [utf8.UTFMax]byte
+	n := utf8.EncodeRune(buf[:], r)
+	return append(b, buf[:n]...)
+}
+
+// Replaces invalid UTF-8 bytes with U+FFFD, like encoding/json does.
+func validUTF8(b []byte) string {
+	if utf8.Valid(b) {
+		return string(b)
+	}
+	s := make([]byte, 0, len(b))
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		if r == utf8.RuneError && size == 1 {
+			s = appendRune(s, utf8.RuneError)
+		} else {
+			s = append(s, b[:size]...)
+		}
+		b = b[size:]
+	}
+	return string(s)
+}
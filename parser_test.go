@@ -0,0 +1,190 @@
+package jason
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestParserMatchesEncodingJSON(t *testing.T) {
+	docs := []string{
+		`{"a": 1, "b": [true, false, null], "c": {"d": "e"}}`,
+		`[-0, 1.5, -2e10, 3E-2, 12345678901234567890, 0.0]`,
+		`"\"\\\/\b\f\n\r\té世😀"`,
+		`"lone \ud800 surrogate \udc00\ud800\n"`,
+		"\"invalid \xff utf-8 \xe4\xb8\"",
+		`{"dup": 1, "dup": 2}`,
+		" \t\r\n [ ] ",
+		`{}`,
+		`"ünïcödé"`,
+	}
+	for _, doc := range docs {
+		var want interface{}
+		d := json.NewDecoder(strings.NewReader(doc))
+		d.UseNumber()
+		if err := d.Decode(&want); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, p := range []*parser{newBytesParser([]byte(doc)), newParser(iotest.OneByteReader(strings.NewReader(doc)))} {
+			got, _, err := p.parse()
+			if err != nil {
+				t.Errorf("%s: %v", doc, err)
+				continue
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: got %#v, want %#v", doc, got, want)
+			}
+		}
+	}
+}
+
+func TestSyntaxErrors(t *testing.T) {
+	cases := []struct {
+		doc          string
+		line, column int
+	}{
+		{`{"a": 1,}`, 1, 9},
+		{"{\n  \"a\": 01\n}", 2, 9},
+		{"[1,\n 2\n 3]", 3, 2},
+		{`{"a" 1}`, 1, 6},
+		{`"abc`, 1, 5},
+		{`[tru]`, 1, 5},
+		{`"\x"`, 1, 3},
+		{"\"ü\t\"", 1, 3},
+		{`-`, 1, 2},
+		{`1.`, 1, 3},
+		{`{"a": [1, 2}`, 1, 12},
+	}
+	for _, c := range cases {
+		_, err := NewValueFromBytes([]byte(c.doc))
+		var e *SyntaxError
+		if !errors.As(err, &e) {
+			t.Errorf("%q: expected a SyntaxError, got %v", c.doc, err)
+			continue
+		}
+		if e.Position.Line != c.line || e.Position.Column != c.column {
+			t.Errorf("%q: got %v (%s)", c.doc, e.Position, e.Msg)
+		}
+	}
+
+	if _, err := NewValue(strings.NewReader(" \n")); err != io.EOF {
+		t.Error(err)
+	}
+	readErr := errors.New("read failed")
+	if _, err := NewValue(io.MultiReader(strings.NewReader(`{"a": `), iotest.ErrReader(readErr))); err != readErr {
+		t.Error(err)
+	}
+}
+
+func TestPositions(t *testing.T) {
+	doc := `{
+  "name": "anton",
+  "tags": ["a", "ü", "c"],
+  "port": "80",
+  "nested": {"deep": [{"x": 1}]}
+}`
+	v, err := NewValue(iotest.HalfReader(bytes.NewReader([]byte(doc))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		value                      *Value
+		line, column, keyLine, key int
+	}{
+		{v, 1, 1, 0, 0},
+		{v.Get("name"), 2, 11, 2, 3},
+		{v.Get("tags").Get(2), 3, 22, 0, 0},
+		{v.Get("tags").Get(-1), 3, 22, 0, 0},
+		{v.Get("nested").Get("deep").Get(0).Get("x"), 5, 29, 5, 24},
+	}
+	for _, c := range cases {
+		pos, keyPos := c.value.Position(), c.value.KeyPosition()
+		if pos.Line != c.line || pos.Column != c.column || keyPos.Line != c.keyLine || keyPos.Column != c.key {
+			t.Errorf("%s: got %v, key %v", c.value.path(), pos, keyPos)
+		}
+	}
+	if pos := v.Get("name").Position(); pos.Offset != strings.Index(doc, `"anton"`) {
+		t.Error(pos.Offset)
+	}
+
+	o, _ := NewObjectFromBytes([]byte(doc))
+	if p, _ := o.GetValue("nested", "deep", "0"); p.Position().Line != 5 {
+		t.Error(p.Position())
+	}
+	if p, _ := o.GetPointer("/nested/deep/0/x"); p.Position().Column != 29 {
+		t.Error(p.Position())
+	}
+	if values, _ := v.Query("$.tags[1]"); len(values) != 1 || values[0].Position().Column != 17 {
+		t.Error(values)
+	}
+
+	_, err = o.GetInt64("port")
+	var e *PathError
+	if !errors.As(err, &e) || e.Position.Line != 4 || e.Position.Column != 11 {
+		t.Error(err)
+	}
+	if err.Error() != `line 4, column 11: path "/port": not a number (expected number, got string)` {
+		t.Error(err)
+	}
+
+	v.Set("/name", "bob")
+	if v.Get("name").Position().IsValid() || !v.Get("port").Position().IsValid() {
+		t.Error("positions not updated after set")
+	}
+	v.Delete("/tags/0")
+	if v.Get("tags").Get(0).Position().Column != 17 || !v.Get("tags").Position().IsValid() {
+		t.Error("positions not updated after delete")
+	}
+	v.Insert("/tags", 0, "new")
+	if v.Get("tags").Get(0).Position().IsValid() || v.Get("tags").Get(1).Position().Column != 17 {
+		t.Error("positions not updated after insert")
+	}
+	if b, _ := NewObjectBuilder().Str("a", "b").Value(); b.Get("a").Position().IsValid() {
+		t.Error("built values have no position")
+	}
+}
+
+func TestPositionsWideObject(t *testing.T) {
+	var b strings.Builder
+	b.WriteString(`{"dup": 0`)
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&b, `, "key%d": %d`, i, i)
+	}
+	b.WriteString(`, "dup": 1}`)
+	doc := b.String()
+	o, err := NewObjectFromBytes([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key, child := range o.Map() {
+		want := strings.LastIndex(doc, `"`+key+`"`)
+		if got := child.KeyPosition().Offset; got != want {
+			t.Errorf("%s: key offset %d, want %d", key, got, want)
+		}
+	}
+
+	o.Set("/key50", "changed")
+	o.Delete("/key10")
+	o.Set("/added", true)
+	if v, _ := o.GetValue("key50"); v.Position().IsValid() {
+		t.Error("changed member kept its position")
+	}
+	if v, _ := o.GetValue("key60"); v.KeyPosition().Offset != strings.Index(doc, `"key60"`) {
+		t.Errorf("key60 moved to %v", v.KeyPosition())
+	}
+	if v, _ := o.GetValue("dup"); v.KeyPosition().Offset != strings.LastIndex(doc, `"dup"`) {
+		t.Errorf("dup at %v", v.KeyPosition())
+	}
+	if v, _ := o.GetValue("added"); v.Position().IsValid() {
+		t.Error("added member has a position")
+	}
+}
@@ -0,0 +1,381 @@
+package jason
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Error values returned when a JSON Patch can't be applied
+var (
+	ErrInvalidPatch = errors.New("invalid patch")
+	ErrTestFailed   = errors.New("test failed")
+)
+
+// PatchError is returned when an operation of a JSON Patch fails.
+// Index is the position of the operation in the patch document.
+type PatchError struct {
+	Index int
+	Op    string
+	Path  string
+	Err   error
+}
+
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("patch operation %d (%s %q): %v", e.Index, e.Op, e.Path, e.Err)
+}
+
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// Applies a JSON Patch (RFC 6902) and returns the patched value.
+// The patch is applied to a copy, so v is left unchanged and nothing is applied if any operation fails.
+// Supports add, remove, replace, move, copy and test.
+// Example:
+//
+//	patch, _ := jason.NewValueFromBytes([]byte(`[{"op": "replace", "path": "/name", "value": "walter"}]`))
+//	patched, err := v.ApplyPatch(patch)
+func (v *Value) ApplyPatch(patch *Value) (*Value, error) {
+	if v.Err != nil {
+		return nil, v.Err
+	}
+
+	ops, ok := patch.raw().([]interface{})
+	if !ok {
+		return nil, &PatchError{Index: -1, Err: ErrNotArray}
+	}
+
+	doc := &Value{data: copyData(v.raw()), exists: true}
+	for i, op := range ops {
+		if err := applyOperation(doc, op); err != nil {
+			err.Index = i
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+func applyOperation(doc *Value, operation interface{}) *PatchError {
+	op, ok := operation.(map[string]interface{})
+	if !ok {
+		return &PatchError{Err: ErrNotObject}
+	}
+
+	name, _ := op["op"].(string)
+	path, ok := op["path"].(string)
+	e := &PatchError{Op: name, Path: path}
+	if !ok {
+		e.Err = fmt.Errorf("%w: missing path", ErrInvalidPatch)
+		return e
+	}
+
+	value, hasValue := op["value"]
+	from, hasFrom := op["from"].(string)
+	if (name == "add" || name == "replace" || name == "test") && !hasValue {
+		e.Err = fmt.Errorf("%w: missing value", ErrInvalidPatch)
+		return e
+	}
+	if (name == "move" || name == "copy") && !hasFrom {
+		e.Err = fmt.Errorf("%w: missing from", ErrInvalidPatch)
+		return e
+	}
+
+	switch name {
+	case "add":
+		e.Err = patchAdd(doc, path, value)
+	case "remove":
+		e.Err = doc.Delete(path)
+	case "replace":
+		if _, e.Err = doc.Pointer(path); e.Err == nil {
+			e.Err = doc.Set(path, &Value{data: value})
+		}
+	case "move":
+		if from == path {
+			return nil
+		}
+		if strings.HasPrefix(path, from+"/") {
+			e.Err = fmt.Errorf("%w: can't move %q into itself", ErrInvalidPatch, from)
+			return e
+		}
+		var moved *Value
+		if moved, e.Err = doc.Pointer(from); e.Err == nil {
+			if e.Err = doc.Delete(from); e.Err == nil {
+				e.Err = patchAdd(doc, path, moved.data)
+			}
+		}
+	case "copy":
+		var copied *Value
+		if copied, e.Err = doc.Pointer(from); e.Err == nil {
+			e.Err = patchAdd(doc, path, copied.data)
+		}
+	case "test":
+		var actual *Value
+		if actual, e.Err = doc.Pointer(path); e.Err == nil && !dataEqual(actual.data, value) {
+			e.Err = ErrTestFailed
+		}
+	default:
+		e.Err = fmt.Errorf("%w: unknown op %q", ErrInvalidPatch, name)
+	}
+
+	if e.Err != nil {
+		return e
+	}
+	return nil
+}
+
+// Adds a value as specified by the add operation:
+// the parent must exist, and array elements are inserted rather than replaced.
+func patchAdd(doc *Value, path string, value interface{}) error {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return &PointerError{Pointer: path, Err: err}
+	}
+	if len(tokens) == 0 {
+		return doc.Set(path, &Value{data: value})
+	}
+
+	parentPath := formatPointer(tokens[:len(tokens)-1])
+	parent, err := doc.Pointer(parentPath)
+	if err != nil {
+		return err
+	}
+
+	last := tokens[len(tokens)-1]
+	if array, ok := parent.data.([]interface{}); ok {
+		index := len(array)
+		if last != "-" {
+			if !isIndexToken(last) {
+				return &PointerError{Pointer: path, Path: path, Err: ErrInvalidIndex, Position: parent.Position()}
+			}
+			if index, err = strconv.Atoi(last); err != nil || index > len(array) {
+				return &PointerError{Pointer: path, Path: path, Err: ErrIndexOutOfRange, Position: parent.Position()}
+			}
+		}
+		return doc.Insert(parentPath, index, &Value{data: value})
+	}
+	return doc.Set(path, &Value{data: value})
+}
+
+// Generates a JSON Patch (RFC 6902) that turns a into b.
+// Objects are compared member by member and arrays are aligned on their longest common
+// subsequence, so unchanged parts of the documents produce no operations.
+// Numbers are compared by value. Returns an empty patch if a and b are equal.
+// Example:
+//
+//	patch := jason.Diff(before, after)
+//	patched, err := before.ApplyPatch(patch)
+func Diff(a, b *Value) *Value {
+	ops := diffData(nil, a.raw(), b.raw(), []interface{}{})
+	return &Value{data: ops, exists: true}
+}
+
+func patchOperation(op string, tokens []string, value interface{}) map[string]interface{} {
+	operation := map[string]interface{}{"op": op, "path": formatPointer(tokens)}
+	if op != "remove" {
+		operation["value"] = copyData(value)
+	}
+	return operation
+}
+
+func diffData(tokens []string, a, b interface{}, ops []interface{}) []interface{} {
+	if dataEqual(a, b) {
+		return ops
+	}
+
+	switch a := a.(type) {
+	case map[string]interface{}:
+		if b, ok := b.(map[string]interface{}); ok {
+			return diffObjects(tokens, a, b, ops)
+		}
+	case []interface{}:
+		if b, ok := b.([]interface{}); ok {
+			return diffArrays(tokens, a, b, ops)
+		}
+	}
+	return append(ops, patchOperation("replace", tokens, b))
+}
+
+func diffObjects(tokens []string, a, b map[string]interface{}, ops []interface{}) []interface{} {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		child := append(tokens[:len(tokens):len(tokens)], key)
+		av, inA := a[key]
+		bv, inB := b[key]
+		switch {
+		case !inB:
+			ops = append(ops, patchOperation("remove", child, nil))
+		case !inA:
+			ops = append(ops, patchOperation("add", child, bv))
+		default:
+			ops = diffData(child, av, bv, ops)
+		}
+	}
+	return ops
+}
+
+func diffArrays(tokens []string, a, b []interface{}, ops []interface{}) []interface{} {
+	// index is the position in the array as it looks after the operations so far.
+	index := 0
+	element := func() []string {
+		return append(tokens[:len(tokens):len(tokens)], strconv.Itoa(index))
+	}
+
+	ai, bj := 0, 0
+	pairs := lcsPairs(len(a), len(b), func(i, j int) bool { return dataEqual(a[i], b[j]) })
+	for _, pair := range append(pairs, [2]int{len(a), len(b)}) {
+		// Unmatched elements are diffed pairwise, the rest is removed or added.
+		removed, added := a[ai:pair[0]], b[bj:pair[1]]
+		for k := 0; k < len(removed) && k < len(added); k++ {
+			ops = diffData(element(), removed[k], added[k], ops)
+			index++
+		}
+		for k := len(added); k < len(removed); k++ {
+			ops = append(ops, patchOperation("remove", element(), nil))
+		}
+		for k := len(removed); k < len(added); k++ {
+			ops = append(ops, patchOperation("add", element(), added[k]))
+			index++
+		}
+		ai, bj = pair[0]+1, pair[1]+1
+		index++
+	}
+	return ops
+}
+
+// Aligns two sequences of length n and m on their longest common subsequence.
+// Returns the index pairs of the matched elements in order.
+// Memory is linear in n and m, time is O(n·m) calls of equal.
+func lcsPairs(n, m int, equal func(i, j int) bool) [][2]int {
+	var pairs [][2]int
+
+	// Only the part between the common prefix and suffix needs aligning.
+	prefix := 0
+	for prefix < n && prefix < m && equal(prefix, prefix) {
+		pairs = append(pairs, [2]int{prefix, prefix})
+		prefix++
+	}
+	suffix := 0
+	for suffix < n-prefix && suffix < m-prefix && equal(n-1-suffix, m-1-suffix) {
+		suffix++
+	}
+
+	pairs = lcsAlign(prefix, n-suffix, prefix, m-suffix, equal, pairs)
+
+	for k := suffix; k > 0; k-- {
+		pairs = append(pairs, [2]int{n - k, m - k})
+	}
+	return pairs
+}
+
+// The largest number of cells of an LCS table that lcsAlign fills at once.
+// Larger parts are split in Hirschberg's way, which needs only two rows at a time.
+var lcsTableMax = 1 << 16
+
+// Appends the pairs of the LCS of the elements i0 to i1 and j0 to j1.
+func lcsAlign(i0, i1, j0, j1 int, equal func(i, j int) bool, pairs [][2]int) [][2]int {
+	rows, cols := i1-i0, j1-j0
+	if rows == 0 || cols == 0 {
+		return pairs
+	}
+	if rows == 1 || rows*cols <= lcsTableMax {
+		return lcsTable(i0, i1, j0, j1, equal, pairs)
+	}
+
+	// Split the second sequence where an LCS crosses the middle of the first one
+	mid := i0 + rows/2
+	forward := lcsForward(i0, mid, j0, j1, equal)
+	backward := lcsBackward(mid, i1, j0, j1, equal)
+	split := 0
+	for k := range forward {
+		if forward[k]+backward[k] > forward[split]+backward[split] {
+			split = k
+		}
+	}
+	pairs = lcsAlign(i0, mid, j0, j0+split, equal, pairs)
+	return lcsAlign(mid, i1, j0+split, j1, equal, pairs)
+}
+
+// Aligns the elements i0 to i1 and j0 to j1 with a full table of LCS lengths.
+func lcsTable(i0, i1, j0, j1 int, equal func(i, j int) bool, pairs [][2]int) [][2]int {
+	// lengths[i][j] is the length of the LCS of the parts starting at i0+i and j0+j.
+	rows, cols := i1-i0, j1-j0
+	lengths := make([][]int, rows+1)
+	for i := range lengths {
+		lengths[i] = make([]int, cols+1)
+	}
+	for i := rows - 1; i >= 0; i-- {
+		for j := cols - 1; j >= 0; j-- {
+			if equal(i0+i, j0+j) {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	for i, j := 0, 0; i < rows && j < cols; {
+		switch {
+		case equal(i0+i, j0+j):
+			pairs = append(pairs, [2]int{i0 + i, j0 + j})
+			i, j = i+1, j+1
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return pairs
+}
+
+// Returns the LCS lengths of the elements i0 to i1 and the elements j0 to j0+k, for every k.
+func lcsForward(i0, i1, j0, j1 int, equal func(i, j int) bool) []int {
+	prev, cur := make([]int, j1-j0+1), make([]int, j1-j0+1)
+	for i := i0; i < i1; i++ {
+		for j := j0; j < j1; j++ {
+			k := j - j0 + 1
+			if equal(i, j) {
+				cur[k] = prev[k-1] + 1
+			} else if prev[k] >= cur[k-1] {
+				cur[k] = prev[k]
+			} else {
+				cur[k] = cur[k-1]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+// Returns the LCS lengths of the elements i0 to i1 and the elements j0+k to j1, for every k.
+func lcsBackward(i0, i1, j0, j1 int, equal func(i, j int) bool) []int {
+	prev, cur := make([]int, j1-j0+1), make([]int, j1-j0+1)
+	for i := i1 - 1; i >= i0; i-- {
+		for j := j1 - 1; j >= j0; j-- {
+			k := j - j0
+			if equal(i, j) {
+				cur[k] = prev[k+1] + 1
+			} else if prev[k] >= cur[k+1] {
+				cur[k] = prev[k]
+			} else {
+				cur[k] = cur[k+1]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
@@ -0,0 +1,198 @@
+package jason
+
+import (
+	"errors"
+	"math/rand"
+	"runtime"
+	"testing"
+)
+
+func TestApplyPatch(t *testing.T) {
+	// Examples from RFC 6902 appendix A
+	cases := []struct {
+		doc, patch, want string
+	}{
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "/baz", "value": "qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{`{"foo": ["bar", "baz"]}`, `[{"op": "add", "path": "/foo/1", "value": "qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"baz": "qux", "foo": "bar"}`, `[{"op": "remove", "path": "/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo": ["bar", "qux", "baz"]}`, `[{"op": "remove", "path": "/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz": "qux", "foo": "bar"}`, `[{"op": "replace", "path": "/baz", "value": "boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			`[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo": ["all", "grass", "cows", "eat"]}`, `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"baz": "qux", "foo": ["a", 2, "c"]}`,
+			`[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2.0}]`,
+			`{"baz":"qux","foo":["a",2,"c"]}`},
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`, `{"child":{"grandchild":{}},"foo":"bar"}`},
+		{`{"foo": ["bar"]}`, `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{`{"a": {"b": 1}}`, `[{"op": "copy", "from": "/a", "path": "/c"}, {"op": "replace", "path": "/c/b", "value": 2}]`, `{"a":{"b":1},"c":{"b":2}}`},
+		{`{"foo": null}`, `[{"op": "test", "path": "/foo", "value": null}]`, `{"foo":null}`},
+		{`{"foo": 1}`, `[{"op": "replace", "path": "", "value": [1]}]`, `[1]`},
+	}
+	for _, c := range cases {
+		doc, err := NewValueFromBytes([]byte(c.doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		patch, err := NewValueFromBytes([]byte(c.patch))
+		if err != nil {
+			t.Fatal(err)
+		}
+		patched, err := doc.ApplyPatch(patch)
+		if err != nil {
+			t.Errorf("%s: %v", c.patch, err)
+			continue
+		}
+		if b, _ := patched.Marshal(); string(b) != c.want {
+			t.Errorf("%s: got %s, want %s", c.patch, b, c.want)
+		}
+	}
+}
+
+func TestApplyPatchErrors(t *testing.T) {
+	cases := []struct {
+		doc, patch string
+		index      int
+		op, path   string
+		err        error
+	}{
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`, 0, "add", "/baz/bat", KeyNotFoundError{"baz"}},
+		{`{"baz": "qux"}`, `[{"op": "test", "path": "/baz", "value": "bar"}]`, 0, "test", "/baz", ErrTestFailed},
+		{`{"foo": "bar"}`, `[{"op": "remove", "path": "/foo"}, {"op": "replace", "path": "/foo", "value": 1}]`, 1, "replace", "/foo", KeyNotFoundError{"foo"}},
+		{`{"foo": ["bar"]}`, `[{"op": "add", "path": "/foo/2", "value": 1}]`, 0, "add", "/foo/2", ErrIndexOutOfRange},
+		{`{"foo": {"a": 1}}`, `[{"op": "move", "from": "/foo", "path": "/foo/b"}]`, 0, "move", "/foo/b", ErrInvalidPatch},
+		{`{"foo": 1}`, `[{"op": "add", "value": 1}]`, 0, "add", "", ErrInvalidPatch},
+		{`{"foo": 1}`, `[{"op": "add", "path": "/bar"}]`, 0, "add", "/bar", ErrInvalidPatch},
+		{`{"foo": 1}`, `[{"op": "copy", "path": "/bar"}]`, 0, "copy", "/bar", ErrInvalidPatch},
+		{`{"foo": 1}`, `[{"op": "invalid", "path": "/bar"}]`, 0, "invalid", "/bar", ErrInvalidPatch},
+	}
+	for _, c := range cases {
+		doc, err := NewValueFromBytes([]byte(c.doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		patch, err := NewValueFromBytes([]byte(c.patch))
+		if err != nil {
+			t.Fatal(err)
+		}
+		patched, err := doc.ApplyPatch(patch)
+		e, ok := err.(*PatchError)
+		if patched != nil || !ok {
+			t.Errorf("%s: expected a PatchError, got %v", c.patch, err)
+			continue
+		}
+		if e.Index != c.index || e.Op != c.op || e.Path != c.path || !errors.Is(err, c.err) {
+			t.Errorf("%s: unexpected error %v", c.patch, e)
+		}
+	}
+}
+
+func TestApplyPatchIsAtomic(t *testing.T) {
+	doc, err := NewObjectFromBytes([]byte(`{"foo": "bar", "list": [1, 2]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch, _ := NewValueFromBytes([]byte(`[
+		{"op": "replace", "path": "/foo", "value": "baz"},
+		{"op": "add", "path": "/list/0", "value": 0},
+		{"op": "test", "path": "/foo", "value": "bar"}
+	]`))
+	if _, err := doc.ApplyPatch(patch); err == nil {
+		t.Fatal("expected an error")
+	}
+	if b, _ := doc.MarshalJSON(); string(b) != `{"foo":"bar","list":[1,2]}` {
+		t.Errorf("document changed: %s", b)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		a, b, patch string
+	}{
+		{`{"a": 1}`, `{"a": 1.0}`, `[]`},
+		{`{"a": 1, "b": 2}`, `{"a": 1, "c": 3}`, `[{"op":"remove","path":"/b"},{"op":"add","path":"/c","value":3}]`},
+		{`{"a": {"b": [1, 2, 3]}}`, `{"a": {"b": [1, 3]}}`, `[{"op":"remove","path":"/a/b/1"}]`},
+		{`[1, 2, 3]`, `[0, 1, 2, 3, 4]`, `[{"op":"add","path":"/0","value":0},{"op":"add","path":"/4","value":4}]`},
+		{`[{"id": 1, "x": "a"}, {"id": 2}]`, `[{"id": 1, "x": "b"}, {"id": 2}]`, `[{"op":"replace","path":"/0/x","value":"b"}]`},
+		{`{"a/b": "x"}`, `{"a/b": ["x"]}`, `[{"op":"replace","path":"/a~1b","value":["x"]}]`},
+		{`"x"`, `{"y": 1}`, `[{"op":"replace","path":"","value":{"y":1}}]`},
+	}
+	for _, c := range cases {
+		a, _ := NewValueFromBytes([]byte(c.a))
+		b, _ := NewValueFromBytes([]byte(c.b))
+		patch := Diff(a, b)
+		if got, _ := patch.Marshal(); string(got) != c.patch {
+			t.Errorf("%s -> %s: got %s, want %s", c.a, c.b, got, c.patch)
+		}
+	}
+}
+
+func TestDiffRoundTrip(t *testing.T) {
+	cases := [][2]string{
+		{`[1, 2, 3, 4, 5]`, `[5, 4, 3, 2, 1]`},
+		{`[1, 2, 3, 4, 5]`, `[2, "x", 4, 6, 7, 8]`},
+		{`{"a": [{"b": [1, {"c": 2}]}], "d": null}`, `{"a": [{"b": [{"c": 3}, 1]}, true], "e": {}}`},
+		{`[]`, `[[], {}, null]`},
+		{`[[1], [2], [3]]`, `[[3]]`},
+	}
+	for _, c := range cases {
+		a, _ := NewValueFromBytes([]byte(c[0]))
+		b, _ := NewValueFromBytes([]byte(c[1]))
+		patch := Diff(a, b)
+		patched, err := a.ApplyPatch(patch)
+		if err != nil {
+			t.Errorf("%s -> %s: %v", c[0], c[1], err)
+			continue
+		}
+		if !dataEqual(patched.raw(), b.raw()) {
+			got, _ := patched.Marshal()
+			p, _ := patch.Marshal()
+			t.Errorf("%s -> %s: got %s with patch %s", c[0], c[1], got, p)
+		}
+	}
+}
+
+func TestLCSPairsSplit(t *testing.T) {
+	defer func(max int) { lcsTableMax = max }(lcsTableMax)
+
+	rng := rand.New(rand.NewSource(1))
+	for run := 0; run < 200; run++ {
+		a, b := make([]int, rng.Intn(40)), make([]int, rng.Intn(40))
+		for i := range a {
+			a[i] = rng.Intn(4)
+		}
+		for j := range b {
+			b[j] = rng.Intn(4)
+		}
+		equal := func(i, j int) bool { return a[i] == b[j] }
+
+		lcsTableMax = 1 << 16
+		want := lcsPairs(len(a), len(b), equal)
+		lcsTableMax = 4
+		got := lcsPairs(len(a), len(b), equal)
+		if len(got) != len(want) {
+			t.Fatalf("%v %v: split LCS has %d pairs, want %d", a, b, len(got), len(want))
+		}
+		for k, pair := range got {
+			if !equal(pair[0], pair[1]) || (k > 0 && (pair[0] <= got[k-1][0] || pair[1] <= got[k-1][1])) {
+				t.Fatalf("%v %v: invalid pairs %v", a, b, got)
+			}
+		}
+	}
+}
+
+func TestLCSPairsMemory(t *testing.T) {
+	// A full table for two arrays that differ everywhere would take n·m ints
+	const n = 2000
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	pairs := lcsPairs(n, n, func(i, j int) bool { return false })
+	runtime.ReadMemStats(&after)
+	if len(pairs) != 0 {
+		t.Error(pairs)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > n*n {
+		t.Errorf("allocated %d bytes", allocated)
+	}
+}
@@ -0,0 +1,161 @@
+package jason
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Error values returned when a JSON Pointer cannot be resolved
+var (
+	ErrInvalidPointer  = errors.New("invalid json pointer")
+	ErrInvalidIndex    = errors.New("invalid array index")
+	ErrIndexOutOfRange = errors.New("index out of range")
+)
+
+// PointerError is returned when a JSON Pointer (RFC 6901) can't be resolved.
+// Path is the part of Pointer that was evaluated when the lookup failed, and Position is where
+// the value it was looked up in starts in the source, if known. Err is one of the errors above,
+// a KeyNotFoundError or ErrNotObject.
+type PointerError struct {
+	Pointer  string
+	Path     string
+	Err      error
+	Position Position
+}
+
+func (e *PointerError) Error() string {
+	msg := fmt.Sprintf("pointer %q: at %q: %v", e.Pointer, e.Path, e.Err)
+	if e.Path == "" || e.Path == e.Pointer {
+		msg = fmt.Sprintf("pointer %q: %v", e.Pointer, e.Err)
+	}
+	if e.Position.IsValid() {
+		msg = fmt.Sprintf("%s: %s", e.Position, msg)
+	}
+	return msg
+}
+
+func (e *PointerError) Unwrap() error {
+	return e.Err
+}
+
+// Resolves a JSON Pointer (RFC 6901) relative to the value.
+// The empty pointer refers to the value itself.
+// Returns a PointerError if the pointer is malformed or does not resolve.
+// Example:
+//
+//	name, err := v.Pointer("/friends/2/name")
+func (v *Value) Pointer(ptr string) (*Value, error) {
+	if v.Err != nil {
+		return nil, v.Err
+	}
+
+	tokens, err := parsePointer(ptr)
+	if err != nil {
+		return nil, &PointerError{Pointer: ptr, Err: err}
+	}
+
+	data := v.raw()
+	current := &Value{data: data, exists: true, parent: v.parent, key: v.key, node: v.node, coerce: v.coerce}
+	for i, token := range tokens {
+		data, err := pointerStep(current.data, token)
+		if err != nil {
+			return nil, &PointerError{Pointer: ptr, Path: formatPointer(tokens[:i+1]), Err: err, Position: current.Position()}
+		}
+		current = current.child(token, data, true)
+	}
+
+	return current, nil
+}
+
+// Gets the value at the JSON Pointer (RFC 6901) relative to the object.
+// Example:
+//
+//	name, err := GetPointer("/friends/2/name")
+func (v *Object) GetPointer(ptr string) (*Value, error) {
+	return v.Pointer(ptr)
+}
+
+// Resolves a single reference token against a map or slice.
+func pointerStep(data interface{}, token string) (interface{}, error) {
+	switch data := data.(type) {
+	case map[string]interface{}:
+		child, ok := data[token]
+		if !ok {
+			return nil, KeyNotFoundError{token}
+		}
+		return child, nil
+	case []interface{}:
+		i, err := pointerIndex(token, len(data))
+		if err != nil {
+			return nil, err
+		}
+		return data[i], nil
+	}
+	return nil, ErrNotObject
+}
+
+// Parses an array index reference token.
+// RFC 6901 only allows non-negative decimals without leading zeros.
+// "-" refers to the (nonexistent) element after the last one and is always out of range here.
+func pointerIndex(token string, length int) (int, error) {
+	if token == "-" {
+		return 0, ErrIndexOutOfRange
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, ErrInvalidIndex
+	}
+	for _, c := range token {
+		if c < '0' || c > '9' {
+			return 0, ErrInvalidIndex
+		}
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i >= length {
+		return 0, ErrIndexOutOfRange
+	}
+	return i, nil
+}
+
+// Splits a JSON Pointer into unescaped reference tokens.
+func parsePointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if ptr[0] != '/' {
+		return nil, ErrInvalidPointer
+	}
+
+	tokens := strings.Split(ptr[1:], "/")
+	for i, token := range tokens {
+		if !strings.Contains(token, "~") {
+			continue
+		}
+		for j := 0; j < len(token); j++ {
+			if token[j] == '~' && (j+1 == len(token) || (token[j+1] != '0' && token[j+1] != '1')) {
+				return nil, ErrInvalidPointer
+			}
+		}
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+// Escapes "~" and "/" in a reference token.
+func escapePointerToken(token string) string {
+	if !strings.ContainsAny(token, "~/") {
+		return token
+	}
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// Joins reference tokens into a JSON Pointer.
+func formatPointer(tokens []string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteByte('/')
+		b.WriteString(escapePointerToken(token))
+	}
+	return b.String()
+}
@@ -0,0 +1,135 @@
+package jason
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const pointerJSON = `{
+	"foo": ["bar", "baz"],
+	"": 0,
+	"a/b": 1,
+	"c%d": 2,
+	"e^f": 3,
+	"g|h": 4,
+	"i\\j": 5,
+	"k\"l": 6,
+	" ": 7,
+	"m~n": 8,
+	"friends": [
+		{"name": "alice"},
+		{"name": "bob"},
+		{"name": "carol", "nothing": null}
+	]
+}`
+
+func TestPointer(t *testing.T) {
+	v, err := NewValue(strings.NewReader(pointerJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		ptr  string
+		want string
+	}{
+		{"/foo", `["bar","baz"]`},
+		{"/foo/0", `"bar"`},
+		{"/", `0`},
+		{"/a~1b", `1`},
+		{"/c%d", `2`},
+		{"/e^f", `3`},
+		{"/g|h", `4`},
+		{"/i\\j", `5`},
+		{"/k\"l", `6`},
+		{"/ ", `7`},
+		{"/m~0n", `8`},
+		{"/friends/2/name", `"carol"`},
+		{"/friends/2/nothing", `null`},
+	}
+	for _, c := range cases {
+		got, err := v.Pointer(c.ptr)
+		if err != nil {
+			t.Errorf("%s: %v", c.ptr, err)
+			continue
+		}
+		b, err := got.Marshal()
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if string(b) != c.want {
+			t.Errorf("%s: got %s, want %s", c.ptr, b, c.want)
+		}
+	}
+
+	whole, err := v.Pointer("")
+	if err != nil || whole.Interface() == nil {
+		t.Error("empty pointer should refer to the whole document", err)
+	}
+
+	if err := whole.Null(); err == nil {
+		t.Error("object should not be null")
+	}
+	nothing, _ := v.Pointer("/friends/2/nothing")
+	if err := nothing.Null(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPointerErrors(t *testing.T) {
+	o, err := NewObjectFromBytes([]byte(pointerJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		ptr  string
+		path string
+		err  error
+	}{
+		{"foo", "", ErrInvalidPointer},
+		{"/m~2n", "", ErrInvalidPointer},
+		{"/foo~", "", ErrInvalidPointer},
+		{"/foo/2", "/foo/2", ErrIndexOutOfRange},
+		{"/foo/-", "/foo/-", ErrIndexOutOfRange},
+		{"/foo/01", "/foo/01", ErrInvalidIndex},
+		{"/foo/-1", "/foo/-1", ErrInvalidIndex},
+		{"/foo/x", "/foo/x", ErrInvalidIndex},
+		{"/foo/0/bar", "/foo/0/bar", ErrNotObject},
+		{"/friends/1/age", "/friends/1/age", KeyNotFoundError{"age"}},
+	}
+	for _, c := range cases {
+		got, err := o.GetPointer(c.ptr)
+		if got != nil {
+			t.Errorf("%s: expected nil value", c.ptr)
+		}
+		e, ok := err.(*PointerError)
+		if !ok {
+			t.Errorf("%s: expected PointerError, got %v", c.ptr, err)
+			continue
+		}
+		if e.Pointer != c.ptr || e.Path != c.path || e.Err != c.err {
+			t.Errorf("%s: unexpected error %#v", c.ptr, e)
+		}
+		if e.Position.IsValid() != (c.path != "") || !errors.Is(err, c.err) {
+			t.Errorf("%s: unexpected position or cause in %v", c.ptr, err)
+		}
+	}
+}
+
+func TestFormatPointer(t *testing.T) {
+	tokens := []string{"a/b", "m~n", "0"}
+	ptr := formatPointer(tokens)
+	if ptr != "/a~1b/m~0n/0" {
+		t.Error(ptr)
+	}
+	parsed, err := parsePointer(ptr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(parsed, ",") != strings.Join(tokens, ",") {
+		t.Error(parsed)
+	}
+}
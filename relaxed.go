@@ -0,0 +1,171 @@
+package jason
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// Skips a // or /* */ comment. Returns false if the next byte doesn't start one.
+// A block comment that isn't closed ends the input with a SyntaxError.
+func (p *parser) skipComment() bool {
+	c, ok := p.peekNext()
+	if !ok || (c != '/' && c != '*') {
+		return false
+	}
+	start := p.pos
+	p.advance()
+	p.advance()
+
+	if c == '/' {
+		for {
+			if c, ok := p.peek(); !ok || c == '\n' {
+				return true
+			}
+			p.advance()
+		}
+	}
+	for {
+		c, ok := p.peek()
+		if !ok {
+			if p.err == nil || p.err == io.EOF {
+				p.err = &SyntaxError{Msg: "unterminated comment", Position: start}
+			}
+			return true
+		}
+		p.advance()
+		if c == '*' {
+			if c, ok := p.peek(); ok && c == '/' {
+				p.advance()
+				return true
+			}
+		}
+	}
+}
+
+// Returns the byte after the next one without consuming anything.
+func (p *parser) peekNext() (byte, bool) {
+	for p.i+1 >= len(p.buf) {
+		if !p.fill() {
+			return 0, false
+		}
+	}
+	return p.buf[p.i+1], true
+}
+
+// Reads an unquoted member name.
+func (p *parser) identifier() (interface{}, error) {
+	start := p.pos
+	b := p.scratch[:0]
+	for {
+		c, ok := p.peek()
+		if !ok || !isIdentifierPart(c) {
+			break
+		}
+		b = append(b, c)
+		p.advance()
+	}
+	p.scratch = b
+	if max := p.limits.MaxStringLength; max > 0 && len(b) > max {
+		return nil, p.limitError("MaxStringLength", max, start)
+	}
+	return validUTF8(b), nil
+}
+
+// Letters, _ and $ start unquoted member names, like in JavaScript. Any non-ASCII character is accepted too.
+func isIdentifierStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isIdentifierPart(c byte) bool {
+	return isIdentifierStart(c) || (c >= '0' && c <= '9')
+}
+
+// Reads the escape sequences that only JSON5 has, after the backslash and c.
+func (p *parser) relaxedEscape(b []byte, c byte, pos Position) ([]byte, error) {
+	switch c {
+	case 'v':
+		return append(b, '\v'), nil
+	case '0':
+		if c, ok := p.peek(); ok && c >= '0' && c <= '9' {
+			return nil, p.syntaxError("invalid character %q in string escape code", c)
+		}
+		return append(b, 0), nil
+	case 'x':
+		var r rune
+		for i := 0; i < 2; i++ {
+			c, ok := p.peek()
+			if !ok {
+				return nil, p.unexpectedEnd()
+			}
+			d, ok := hexDigit(c)
+			if !ok {
+				return nil, p.syntaxError("invalid character %q in \\x hexadecimal character escape", c)
+			}
+			r = r<<4 | rune(d)
+			p.advance()
+		}
+		return appendRune(b, r), nil
+	case '\r':
+		// Line continuation
+		if c, ok := p.peek(); ok && c == '\n' {
+			p.advance()
+		}
+		return b, nil
+	case '\n':
+		return b, nil
+	}
+	if c >= '1' && c <= '9' {
+		return nil, &SyntaxError{Msg: fmt.Sprintf("invalid character %q in string escape code", c), Position: pos}
+	}
+	// Any other character stands for itself, e.g. \'
+	return append(b, c), nil
+}
+
+// Reads Infinity or NaN after an optional sign as null. JSON has no numbers for them,
+// and null is what JavaScript's JSON.stringify writes instead.
+func (p *parser) namedNumber(c byte) (interface{}, error) {
+	if c == 'N' {
+		return nil, p.literal("NaN")
+	}
+	return nil, p.literal("Infinity")
+}
+
+// Reads a hexadecimal number after the 0. It is converted to decimal, so it can be read like any other number.
+func (p *parser) hexNumber(sign string, start Position) (interface{}, error) {
+	p.advance()
+	b := p.scratch[:0]
+	for {
+		c, ok := p.peek()
+		if _, hex := hexDigit(c); !ok || !hex {
+			break
+		}
+		b = append(b, c)
+		p.advance()
+	}
+	p.scratch = b
+	if len(b) == 0 {
+		if c, ok := p.peek(); ok {
+			return nil, p.syntaxError("invalid character %q in hexadecimal literal", c)
+		}
+		return nil, p.unexpectedEnd()
+	}
+	if max := p.limits.MaxNumberLength; max > 0 && len(sign)+len(b)+2 > max {
+		return nil, p.limitError("MaxNumberLength", max, start)
+	}
+	n, _ := new(big.Int).SetString(string(b), 16)
+	return json.Number(sign + n.String()), nil
+}
+
+func hexDigit(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
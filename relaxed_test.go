@@ -0,0 +1,107 @@
+package jason
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRelaxed(t *testing.T) {
+	cases := []struct {
+		relaxed, standard string
+	}{
+		{"// comment\n{\"a\": 1} // trailing", `{"a": 1}`},
+		{"{/* inline */\"a\" /**/: /* x */ 1 /* * / */}", `{"a": 1}`},
+		{"[1, 2, ]", `[1, 2]`},
+		{"{\"a\": [1,], \"b\": {\"c\": 2,},}", `{"a": [1], "b": {"c": 2}}`},
+		{`{a: 1, $b_2: 2, _: 3, ünï: 4}`, `{"a": 1, "$b_2": 2, "_": 3, "ünï": 4}`},
+		{`{'a': 'it\'s "quoted"'}`, `{"a": "it's \"quoted\""}`},
+		{`['\x41\v\0', 'a\
+b', "\q"]`, `["A\u000b\u0000", "ab", "q"]`},
+		{`[0x1F, -0Xff, +0x0, 0xFFFFFFFFFFFFFFFFFF]`, `[31, -255, 0, 4722366482869645213695]`},
+		{`[+1, +1.5e3, .5, -.5, 5., 5.e1]`, `[1, 1.5e3, 0.5, -0.5, 5.0, 5.0e1]`},
+		{"\v[\f1\f]", `[1]`},
+		{`[Infinity, -Infinity, +Infinity, NaN, -NaN]`, `[null, null, null, null, null]`},
+		{`[]`, `[]`},
+	}
+	for _, c := range cases {
+		v, err := ParseOptions{Relaxed: true}.NewValueFromBytes([]byte(c.relaxed))
+		if err != nil {
+			t.Errorf("%s: %v", c.relaxed, err)
+			continue
+		}
+		want, err := NewValueFromBytes([]byte(c.standard))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(v.raw(), want.raw()) {
+			t.Errorf("%s: got %v, want %v", c.relaxed, v.raw(), want.raw())
+		}
+		if _, err := NewValueFromBytes([]byte(c.relaxed)); err == nil && c.relaxed != c.standard {
+			t.Errorf("%s: accepted without Relaxed", c.relaxed)
+		}
+	}
+
+	// Relaxed documents marshal to standard JSON
+	v, _ := ParseOptions{Relaxed: true}.NewValueFromBytes([]byte("{a: [0x10, +.5, 'x', -Infinity, NaN], // c\n}"))
+	b, err := v.Marshal()
+	if err != nil || string(b) != `{"a":[16,0.5,"x",null,null]}` {
+		t.Errorf("Marshal() = %s, %v", b, err)
+	}
+	if back, err := NewValueFromBytes(b); err != nil || !back.Equal(v) {
+		t.Errorf("%s doesn't parse back: %v", b, err)
+	}
+
+	inf := v.Get("a").Get(3)
+	if _, err := inf.Float64(); !inf.IsNull() || !errors.Is(err, ErrNotNumber) {
+		t.Errorf("-Infinity: IsNull() = %v, Float64() error = %v", inf.IsNull(), err)
+	}
+
+	// Positions still point into the source
+	v, _ = ParseOptions{Relaxed: true}.NewValueFromBytes([]byte("{\n  // comment\n  port: 'x', // why\n}"))
+	if pos := v.Get("port").Position(); pos.Line != 3 || pos.Column != 9 {
+		t.Error(pos)
+	}
+}
+
+func TestRelaxedErrors(t *testing.T) {
+	cases := []string{
+		`[1 /* unterminated`,
+		`/* unterminated`,
+		`[1, / 2]`,
+		`[1,,]`,
+		`[,]`,
+		`{,}`,
+		`{a b: 1}`,
+		`{1a: 1}`,
+		`['\1']`,
+		`['\x4']`,
+		`[0x]`,
+		`[0xg]`,
+		`[.]`,
+		`[+-1]`,
+		`[Infinite]`,
+		`[-NaNa]`,
+		`['a"]`,
+	}
+	for _, doc := range cases {
+		_, err := ParseOptions{Relaxed: true}.NewValueFromBytes([]byte(doc))
+		var e *SyntaxError
+		if !errors.As(err, &e) {
+			t.Errorf("%s: expected a SyntaxError, got %v", doc, err)
+		}
+		_, err = ParseOptions{Relaxed: true}.NewValue(strings.NewReader(doc))
+		if !errors.As(err, &e) {
+			t.Errorf("%s: expected a SyntaxError from a reader, got %v", doc, err)
+		}
+	}
+
+	_, err := ParseOptions{Relaxed: true}.NewValueFromBytes([]byte("[1,\n /* unterminated"))
+	if err == nil || err.Error() != "line 2, column 2: unterminated comment" {
+		t.Error(err)
+	}
+	if _, err := (ParseOptions{Relaxed: true, Strict: true}).NewValueFromBytes([]byte("{} // done\n/* really */")); err != nil {
+		t.Error(err)
+	}
+}
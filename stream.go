@@ -0,0 +1,154 @@
+package jason
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// RecordError is returned by StreamReader when a line doesn't hold a single valid JSON value.
+type RecordError struct {
+	Line int
+	Err  error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("invalid record on line %d: %v", e.Line, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// StreamOption configures a StreamReader.
+type StreamOption func(*StreamReader)
+
+// Skips records that are not valid JSON instead of stopping at them.
+// fn is called with the error of every skipped record and may be nil.
+func SkipInvalidRecords(fn func(err *RecordError)) StreamOption {
+	return func(s *StreamReader) {
+		s.skipInvalid = true
+		s.onSkip = fn
+	}
+}
+
+// StreamReader reads newline delimited JSON (NDJSON, JSON Lines), one value per line.
+// Blank lines are ignored and lines may be of any length.
+type StreamReader struct {
+	r           *bufio.Reader
+	buf         []byte
+	line        int
+	offset      int
+	value       *Value
+	err         error
+	done        bool
+	skipInvalid bool
+	onSkip      func(err *RecordError)
+}
+
+// Creates a reader for newline delimited JSON.
+// Example:
+//
+//	s := jason.NewStreamReader(file)
+//	for s.Next() {
+//		id, err := s.Value().Get("id").Int64()
+//		...
+//	}
+//	if err := s.Err(); err != nil {
+//		...
+//	}
+func NewStreamReader(r io.Reader, opts ...StreamOption) *StreamReader {
+	s := &StreamReader{r: bufio.NewReader(r)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Advances to the next record. Returns false at the end of the input or when an error occurred,
+// which is then reported by Err.
+func (s *StreamReader) Next() bool {
+	s.value = nil
+	for !s.done {
+		line, err := s.readLine()
+		if err != nil {
+			s.done = true
+			if err != io.EOF {
+				s.err = err
+				return false
+			}
+		}
+		if len(line) == 0 {
+			continue
+		}
+
+		s.line++
+		offset := s.offset
+		s.offset += len(line)
+		line = bytes.TrimSuffix(line, []byte("\n"))
+		p := newBytesParser(bytes.TrimSuffix(line, []byte("\r")))
+		p.pos = Position{Offset: offset, Line: s.line, Column: 1}
+
+		data, node, err := p.parse()
+		if err == io.EOF {
+			// Blank line
+			continue
+		}
+		if err == nil {
+			err = p.end()
+		}
+		if err != nil {
+			e := &RecordError{Line: s.line, Err: err}
+			if s.skipInvalid {
+				if s.onSkip != nil {
+					s.onSkip(e)
+				}
+				continue
+			}
+			s.err = e
+			s.done = true
+			return false
+		}
+
+		s.value = &Value{data: data, exists: true, node: node}
+		return true
+	}
+	return false
+}
+
+// Reads a line including the newline, however long it is.
+func (s *StreamReader) readLine() ([]byte, error) {
+	s.buf = s.buf[:0]
+	for {
+		chunk, err := s.r.ReadSlice('\n')
+		s.buf = append(s.buf, chunk...)
+		if err != bufio.ErrBufferFull {
+			return s.buf, err
+		}
+	}
+}
+
+// Returns the current record.
+func (s *StreamReader) Value() *Value {
+	return s.value
+}
+
+// Returns the current record as an object.
+// Returns an error if the record is not an object.
+func (s *StreamReader) Object() (*Object, error) {
+	if s.value == nil {
+		return nil, ErrNilValue
+	}
+	return s.value.Object()
+}
+
+// Returns the line number of the current record, starting at 1.
+func (s *StreamReader) Line() int {
+	return s.line
+}
+
+// Returns the error that stopped the reader, or nil at the end of the input.
+func (s *StreamReader) Err() error {
+	return s.err
+}
@@ -0,0 +1,105 @@
+package jason
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStreamReader(t *testing.T) {
+	long := strings.Repeat("x", 100000)
+	input := "{\"id\": 1}\n\n  \r\n{\"id\": 2, \"long\": \"" + long + "\"}\r\n[3]\n{\"id\": 4}"
+
+	s := NewStreamReader(strings.NewReader(input))
+	var lines []int
+	var values []string
+	for s.Next() {
+		lines = append(lines, s.Line())
+		b, _ := s.Value().Marshal()
+		values = append(values, string(b))
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 4 || values[0] != `{"id":1}` || values[2] != `[3]` || values[3] != `{"id":4}` {
+		t.Error(values)
+	}
+	if len(lines) != 4 || lines[1] != 4 || lines[3] != 6 {
+		t.Error(lines)
+	}
+	if s.Next() || s.Value() != nil {
+		t.Error("expected the end of the stream")
+	}
+}
+
+func TestStreamReaderObjects(t *testing.T) {
+	s := NewStreamReader(strings.NewReader("{\"id\": 1}\n[2]\n"))
+	if !s.Next() {
+		t.Fatal(s.Err())
+	}
+	if o, err := s.Object(); err != nil {
+		t.Error(err)
+	} else if id, _ := o.GetInt64("id"); id != 1 {
+		t.Error(id)
+	}
+	if !s.Next() {
+		t.Fatal(s.Err())
+	}
+	if _, err := s.Object(); !errors.Is(err, ErrNotObject) {
+		t.Error(err)
+	}
+}
+
+func TestStreamReaderErrors(t *testing.T) {
+	input := "{\"id\": 1}\n{\"id\": \n{\"id\": 3} 4\n{\"id\": 4}\n"
+
+	s := NewStreamReader(strings.NewReader(input))
+	count := 0
+	for s.Next() {
+		count++
+	}
+	var e *RecordError
+	if count != 1 || !errors.As(s.Err(), &e) || e.Line != 2 {
+		t.Fatal(count, s.Err())
+	}
+	var syntax *SyntaxError
+	if !errors.As(s.Err(), &syntax) || syntax.Position.Line != 2 {
+		t.Error(s.Err())
+	}
+
+	var skipped []int
+	s = NewStreamReader(strings.NewReader(input), SkipInvalidRecords(func(err *RecordError) {
+		skipped = append(skipped, err.Line)
+	}))
+	var ids []int64
+	for s.Next() {
+		id, _ := s.Value().Get("id").Int64()
+		ids = append(ids, id)
+	}
+	if s.Err() != nil {
+		t.Error(s.Err())
+	}
+	if len(ids) != 2 || ids[1] != 4 || len(skipped) != 2 || skipped[0] != 2 || skipped[1] != 3 {
+		t.Error(ids, skipped)
+	}
+
+	s = NewStreamReader(strings.NewReader(input), SkipInvalidRecords(nil))
+	count = 0
+	for s.Next() {
+		count++
+	}
+	if count != 2 {
+		t.Error(count)
+	}
+}
+
+func TestStreamReaderPositions(t *testing.T) {
+	s := NewStreamReader(strings.NewReader("{\"id\": 1}\n{\"id\": \"2\"}\n"))
+	s.Next()
+	s.Next()
+	_, err := s.Value().Get("id").Int64()
+	var e *PathError
+	if !errors.As(err, &e) || e.Position.Line != 2 || e.Position.Column != 8 || e.Position.Offset != 17 {
+		t.Error(err)
+	}
+}